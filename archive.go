@@ -0,0 +1,78 @@
+package goscraper
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Archive stores the raw responses of the documents fetched, see Scraper.Archive
+type Archive interface {
+	Store(record *ArchiveRecord) error
+}
+
+// ArchiveRecord is a response as fetched, its body decompressed but not converted to UTF-8
+type ArchiveRecord struct {
+	Url        string      `json:"url"`
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	Time       time.Time   `json:"time"`
+}
+
+// JSONArchive writes the records to a writer as JSON lines, it's safe for concurrent use
+type JSONArchive struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func NewJSONArchive(w io.Writer) *JSONArchive {
+	return &JSONArchive{enc: json.NewEncoder(w)}
+}
+
+func (a *JSONArchive) Store(record *ArchiveRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.enc.Encode(record)
+}
+
+func (scraper *Scraper) archiveResponse(resp *http.Response) {
+	if scraper.Archive == nil {
+		return
+	}
+	record := &ArchiveRecord{
+		Url:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header.Clone(),
+		Time:       time.Now(),
+	}
+	resp.Body = &archivedBody{ReadCloser: resp.Body, record: record}
+	scraper.records = append(scraper.records, record)
+}
+
+func (scraper *Scraper) storeArchive() error {
+	if scraper.Archive == nil {
+		return nil
+	}
+	records := scraper.records
+	scraper.records = nil
+	for _, record := range records {
+		if err := scraper.Archive.Store(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type archivedBody struct {
+	io.ReadCloser
+	record *ArchiveRecord
+}
+
+func (b *archivedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.record.Body = append(b.record.Body, p[:n]...)
+	return n, err
+}
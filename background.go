@@ -0,0 +1,57 @@
+package goscraper
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	cssUrlRegexp = regexp.MustCompile(`url\(\s*['"]?([^'")]+?)['"]?\s*\)`)
+	// heroRegexp matches the class or id of the main banner of landing pages
+	heroRegexp = regexp.MustCompile(`(?i)hero|banner|masthead|jumbotron|splash|cover`)
+)
+
+// backgroundExtractor collects the images of inline style="background-image:url(…)" attributes,
+// those of hero sections rank above body images, the others below
+type backgroundExtractor struct{}
+
+func (e *backgroundExtractor) Token(page *Page, token html.Token) error {
+	if token.Type != html.StartTagToken && token.Type != html.SelfClosingTagToken {
+		return nil
+	}
+	var style string
+	var hero bool
+	for _, attr := range token.Attr {
+		switch attr.Key {
+		case "style":
+			style = attr.Val
+		case "class", "id":
+			hero = hero || heroRegexp.MatchString(attr.Val)
+		}
+	}
+	if !strings.Contains(strings.ToLower(style), "background") {
+		return nil
+	}
+	for _, match := range cssUrlRegexp.FindAllStringSubmatch(style, -1) {
+		src := strings.TrimSpace(match[1])
+		if len(src) == 0 || strings.HasPrefix(strings.ToLower(src), "data:") {
+			continue
+		}
+		imgUrl, err := page.Resolve(src)
+		if err != nil {
+			return err
+		}
+		if hero {
+			page.images.hero = append(page.images.hero, Image{Url: imgUrl})
+		} else {
+			page.images.background = append(page.images.background, Image{Url: imgUrl})
+		}
+	}
+	return nil
+}
+
+func (e *backgroundExtractor) Finalize(page *Page) error {
+	return nil
+}
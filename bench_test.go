@@ -0,0 +1,81 @@
+package goscraper
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+// benchPage is a typical article page, its head full of meta tags and its body of images
+var benchPage = func() string {
+	var b strings.Builder
+	b.WriteString(`<!DOCTYPE html><html lang="en"><head><meta charset="utf-8"><title>Benchmark article</title>
+<meta name="Description" content="An article to benchmark the extraction">
+<meta property="OG:Title" content="Benchmark article"><meta property="og:site_name" content="Bench">
+<meta property="og:type" content="article"><meta name="twitter:card" content="summary_large_image">
+<meta name="theme-color" content="#123456" media="(prefers-color-scheme: light)">
+<link rel="Stylesheet preload" href="/style.css"><link rel="preconnect" href="https://cdn.example.com">
+<link rel="alternate" type="application/rss+xml" href="/feed.xml" title="Feed">
+<link rel="icon" href="/favicon.png"><link rel="canonical" href="http://example.com/article">
+</head><body><nav><a href="/">Home</a></nav><article><h1>Benchmark article</h1>`)
+	for i := 0; i < 50; i++ {
+		b.WriteString(`<p class="text">A paragraph of the article with a <a href="/link" rel="nofollow noopener">link</a>.</p>
+<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=" data-src="/img.jpg" alt="An image" width="640" height="480" loading="lazy">`)
+	}
+	b.WriteString(`<video poster="/poster.jpg"><source src="/v.mp4" type="video/mp4"></video></article></body></html>`)
+	return b.String()
+}()
+
+func BenchmarkParse(b *testing.B) {
+	record := &ArchiveRecord{
+		Url:        "http://example.com/article",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+		Body:       []byte(benchPage),
+	}
+	scraper := &Scraper{}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchPage)))
+	for i := 0; i < b.N; i++ {
+		if _, err := scraper.Replay(record); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkImageSrc(b *testing.B) {
+	attrs := []html.Attribute{
+		{Key: "src", Val: "data:image/gif;base64,R0lGODlhAQABAAAAACw="}, {Key: "data-src", Val: "/img.jpg"},
+		{Key: "alt", Val: "An image"}, {Key: "width", Val: "640"}, {Key: "height", Val: "480"}, {Key: "loading", Val: "lazy"},
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		imageSrc(attrs)
+	}
+}
+
+func BenchmarkHasRel(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		hasRel("Stylesheet preload nofollow", "preconnect", "dns-prefetch")
+	}
+}
+
+func BenchmarkMetaExtractor(b *testing.B) {
+	page := &Page{Doc: &Document{}}
+	tokens := []html.Token{
+		{Type: html.SelfClosingTagToken, Data: "meta", Attr: []html.Attribute{{Key: "property", Val: "OG:Title"}, {Key: "content", Val: "Title"}}},
+		{Type: html.SelfClosingTagToken, Data: "meta", Attr: []html.Attribute{{Key: "name", Val: "Description"}, {Key: "content", Val: "Description"}}},
+		{Type: html.SelfClosingTagToken, Data: "meta", Attr: []html.Attribute{{Key: "name", Val: "Theme-Color"}, {Key: "content", Val: "#fff"}, {Key: "media", Val: "(prefers-color-scheme: Light)"}}},
+	}
+	e := &metaExtractor{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		page.Doc.Meta = nil
+		for _, token := range tokens {
+			e.Token(page, token)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package goscraper
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlocklistSource opens a list of blocked hosts
+type BlocklistSource func(ctx context.Context) (io.ReadCloser, error)
+
+// BlocklistURL is a source downloading the list at u
+func BlocklistURL(u string) BlocklistSource {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s: %w", u, ErrHTTPStatus{Code: resp.StatusCode})
+		}
+		return resp.Body, nil
+	}
+}
+
+// BlocklistFile is a source reading the list of the file at path
+func BlocklistFile(path string) BlocklistSource {
+	return func(ctx context.Context) (io.ReadCloser, error) {
+		return os.Open(path)
+	}
+}
+
+// Blocklist refuses to fetch the hosts of malware or phishing lists, and their subdomains.
+// The lists are either hosts files, plain lists of domains or urls, or adblock filters
+// of whole domains (||example.com^). A single blocklist is meant to be shared by all the
+// scrapers of a service, see Run to keep it up to date.
+type Blocklist struct {
+	Sources []BlocklistSource
+	// Refresh is the delay between two loads of the sources by Run
+	Refresh time.Duration
+
+	mu sync.RWMutex
+	// lists are the hosts of each source, a source failing to load keeps its previous hosts
+	lists  []map[string]struct{}
+	loaded time.Time
+}
+
+// NewBlocklist returns a blocklist of the hosts listed by sources, refreshed every refresh by Run
+func NewBlocklist(refresh time.Duration, sources ...BlocklistSource) *Blocklist {
+	return &Blocklist{Sources: sources, Refresh: refresh}
+}
+
+// Load loads the sources of the blocklist, it returns the error of the first one that failed
+func (b *Blocklist) Load(ctx context.Context) error {
+	lists := make([]map[string]struct{}, len(b.Sources))
+	var errs []error
+	for i, source := range b.Sources {
+		hosts, err := loadBlocklist(ctx, source)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		lists[i] = hosts
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := range lists {
+		if lists[i] == nil && i < len(b.lists) {
+			lists[i] = b.lists[i]
+		}
+	}
+	b.lists = lists
+	b.loaded = time.Now()
+	if len(errs) > 0 {
+		return fmt.Errorf("goscraper: %d of %d blocklists failed to load: %w", len(errs), len(b.Sources), errs[0])
+	}
+	return nil
+}
+
+// Run loads the sources of the blocklist every Refresh, an hour by default, until ctx is done.
+// The errors of the loads are passed to onError if it's not nil.
+func (b *Blocklist) Run(ctx context.Context, onError func(error)) {
+	refresh := b.Refresh
+	if refresh <= 0 {
+		refresh = time.Hour
+	}
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+	for {
+		if err := b.Load(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Loaded returns the time of the last load
+func (b *Blocklist) Loaded() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.loaded
+}
+
+// Len returns the number of hosts blocked, the same host may be counted once by source
+func (b *Blocklist) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	n := 0
+	for _, hosts := range b.lists {
+		n += len(hosts)
+	}
+	return n
+}
+
+// Blocked reports whether host or one of its parent domains is blocked
+func (b *Blocklist) Blocked(host string) bool {
+	host = normalizeHost(host)
+	if len(host) == 0 {
+		return false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for {
+		for _, hosts := range b.lists {
+			if _, ok := hosts[host]; ok {
+				return true
+			}
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			return false
+		}
+		host = host[i+1:]
+	}
+}
+
+func (scraper *Scraper) checkBlocklist(u *url.URL) error {
+	if err := scraper.checkTenant(u); err != nil {
+		return err
+	}
+	if scraper.Blocklist != nil && scraper.Blocklist.Blocked(u.Hostname()) {
+		return fmt.Errorf("%w: %s", ErrBlocked, u.Hostname())
+	}
+	return nil
+}
+
+func loadBlocklist(ctx context.Context, source BlocklistSource) (map[string]struct{}, error) {
+	r, err := source(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	hosts := map[string]struct{}{}
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 4096), 1<<20)
+	for s.Scan() {
+		if host := blocklistHost(s.Text()); len(host) > 0 {
+			hosts[host] = struct{}{}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return hosts, nil
+}
+
+func blocklistHost(line string) string {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+	line = strings.TrimSpace(line)
+	if len(line) == 0 || line[0] == '!' || line[0] == '[' {
+		return ""
+	}
+	if strings.HasPrefix(line, "||") {
+		// adblock filters, only those blocking a whole domain
+		line = strings.TrimPrefix(line, "||")
+		if !strings.HasSuffix(line, "^") {
+			return ""
+		}
+		return normalizeHost(strings.TrimSuffix(line, "^"))
+	}
+	fields := strings.Fields(line)
+	if len(fields) > 1 && net.ParseIP(fields[0]) != nil {
+		// hosts files: 0.0.0.0 example.com
+		fields = fields[1:]
+	}
+	host := fields[0]
+	if strings.Contains(host, "://") {
+		u, err := url.Parse(host)
+		if err != nil {
+			return ""
+		}
+		host = u.Hostname()
+	}
+	switch host = normalizeHost(host); host {
+	case "localhost", "localhost.localdomain", "local", "broadcasthost", "0.0.0.0":
+		return ""
+	}
+	return host
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimPrefix(strings.TrimSuffix(strings.ToLower(host), "."), "*.")
+}
@@ -0,0 +1,67 @@
+package goscraper
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker stops requests to a host for Cooldown after Threshold consecutive failures.
+// A single breaker is meant to be shared by all the scrapers of a batch.
+type CircuitBreaker struct {
+	Threshold int
+	Cooldown  time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*circuit
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+}
+
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{Threshold: threshold, Cooldown: cooldown, hosts: map[string]*circuit{}}
+}
+
+func (b *CircuitBreaker) allow(host string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.hosts[host]
+	if ok && time.Now().Before(c.openUntil) {
+		return fmt.Errorf("%w: %s", ErrCircuitOpen, host)
+	}
+	return nil
+}
+
+// record counts the result of a request to host, once the circuit has been opened
+// a single failure after the cooldown is enough to open it again
+func (b *CircuitBreaker) record(host string, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.hosts == nil {
+		b.hosts = map[string]*circuit{}
+	}
+	if !failed {
+		delete(b.hosts, host)
+		return
+	}
+	c, ok := b.hosts[host]
+	if !ok {
+		c = &circuit{}
+		b.hosts[host] = c
+	}
+	c.failures++
+	if c.failures >= b.Threshold {
+		c.openUntil = time.Now().Add(b.Cooldown)
+	}
+}
+
+func hostFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !refused(err)
+	}
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
@@ -0,0 +1,115 @@
+// Package browser renders documents in a headless Chrome before they are scraped,
+// for sites filling their meta tags with JavaScript.
+//
+//	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), chromedp.DefaultExecAllocatorOptions[:]...)
+//	defer cancel()
+//	browserCtx, cancel := chromedp.NewContext(allocCtx)
+//	defer cancel()
+//	s := &goscraper.Scraper{Url: u, MaxRedirect: 5, Fetcher: &browser.Fetcher{Context: browserCtx}}
+package browser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// Fetcher loads pages in a tab of a headless Chrome and returns the rendered html as a
+// text/html response. Chrome follows the redirects itself: the response's request holds the
+// final url but the scraper's redirect settings don't apply.
+type Fetcher struct {
+	// Context is the chromedp context of a browser, each request opens a tab in it.
+	// A new browser is started for every request when nil.
+	Context context.Context
+	// Wait is waited for after the page is loaded for scripts to update the document
+	Wait time.Duration
+}
+
+func (f *Fetcher) Do(req *http.Request) (*http.Response, error) {
+	parent := f.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := chromedp.NewContext(parent)
+	defer cancel()
+	// close the tab when the request is cancelled
+	go func() {
+		select {
+		case <-req.Context().Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var mu sync.Mutex
+	var documents []*network.Response
+	chromedp.ListenTarget(ctx, func(ev interface{}) {
+		if e, ok := ev.(*network.EventResponseReceived); ok && e.Type == network.ResourceTypeDocument {
+			mu.Lock()
+			documents = append(documents, e.Response)
+			mu.Unlock()
+		}
+	})
+
+	headers := network.Headers{}
+	for key := range req.Header {
+		// chrome negotiates the encoding itself
+		if !strings.EqualFold(key, "User-Agent") && !strings.EqualFold(key, "Accept-Encoding") {
+			headers[key] = req.Header.Get(key)
+		}
+	}
+	actions := []chromedp.Action{network.Enable(), network.SetExtraHTTPHeaders(headers)}
+	if ua := req.Header.Get("User-Agent"); len(ua) > 0 {
+		actions = append(actions, emulation.SetUserAgentOverride(ua))
+	}
+	var location, content string
+	actions = append(actions,
+		chromedp.Navigate(req.URL.String()),
+		chromedp.Sleep(f.Wait),
+		chromedp.Location(&location),
+		chromedp.OuterHTML("html", &content, chromedp.ByQuery),
+	)
+	if err := chromedp.Run(ctx, actions...); err != nil {
+		if req.Context().Err() != nil {
+			return nil, req.Context().Err()
+		}
+		return nil, fmt.Errorf("browser: %s: %w", req.URL, err)
+	}
+
+	final, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	status := http.StatusOK
+	mu.Lock()
+	for _, doc := range documents {
+		// iframes are documents too, keep the status of the page itself
+		if doc.URL == location {
+			status = int(doc.Status)
+		}
+	}
+	mu.Unlock()
+
+	rendered := req.Clone(req.Context())
+	rendered.URL = final
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+		Body:          io.NopCloser(strings.NewReader(content)),
+		ContentLength: int64(len(content)),
+		Request:       rendered,
+	}, nil
+}
@@ -0,0 +1,77 @@
+package goscraper
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxCachedHosts bounds each cache, batch crawls over many hosts would grow them forever
+const maxCachedHosts = 10000
+
+// hostCache holds values by host until they expire. Once full, the expired entries are
+// dropped, then the tenth of the entries expiring first.
+type hostCache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func newHostCache() *hostCache {
+	return &hostCache{max: maxCachedHosts, entries: map[string]cacheEntry{}}
+}
+
+func (c *hostCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (c *hostCache) put(key string, value interface{}, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.max {
+		c.evict()
+	}
+	c.entries[key] = cacheEntry{value: value, expires: expires}
+}
+
+func (c *hostCache) size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// evict makes room in a full cache, c.mu must be held
+func (c *hostCache) evict() {
+	now := time.Now()
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) < c.max {
+		return
+	}
+	keys := make([]string, 0, len(c.entries))
+	for key := range c.entries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return c.entries[keys[i]].expires.Before(c.entries[keys[j]].expires) })
+	for _, key := range keys[:len(keys)/10+1] {
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,29 @@
+package goscraper
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHostCache(t *testing.T) {
+	c := newHostCache()
+	c.max = 10
+	now := time.Now()
+	c.put("expired", 1, now.Add(-time.Second))
+	if _, ok := c.get("expired"); ok {
+		t.Errorf("got an expired entry")
+	}
+	for i := 0; i < 20; i++ {
+		c.put(fmt.Sprint(i), i, now.Add(time.Duration(i+1)*time.Minute))
+	}
+	if n := c.size(); n > c.max {
+		t.Errorf("got %d entries, want at most %d", n, c.max)
+	}
+	if _, ok := c.get("0"); ok {
+		t.Errorf("the entry expiring first was kept")
+	}
+	if v, ok := c.get("19"); !ok || v != 19 {
+		t.Errorf("got %v, %v for the last entry, want 19", v, ok)
+	}
+}
@@ -0,0 +1,249 @@
+// Package card composes shareable card images from previews: their title, description,
+// lead image, icon and brand color, e.g. to serve as the og:image of link aggregation pages.
+//
+//	doc, err := s.Scrape()
+//	...
+//	err = card.WritePNG(ctx, w, doc.Preview, nil)
+package card
+
+import (
+	"context"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/badoux/goscraper"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	defaultWidth  = 1200
+	defaultHeight = 630
+	maxImageSize  = 10 << 20
+)
+
+var (
+	defaultColor = color.RGBA{0x33, 0x33, 0x33, 0xff}
+	textColor    = color.RGBA{0x1a, 0x1a, 0x1a, 0xff}
+	mutedColor   = color.RGBA{0x5f, 0x63, 0x68, 0xff}
+)
+
+// Options are the options of the cards rendered, the zero value renders 1200x630 cards
+type Options struct {
+	Width, Height int
+	// Color is the brand color of the cards of previews without ThemeColor
+	Color color.Color
+	// Fetcher downloads the lead image and icon of the previews, http.DefaultClient by default
+	Fetcher goscraper.Fetcher
+}
+
+// WritePNG renders the card of preview and writes it to w as a png image
+func WritePNG(ctx context.Context, w io.Writer, preview goscraper.DocumentPreview, opts *Options) error {
+	img, err := Render(ctx, preview, opts)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// Render renders the card of preview: the brand color on top, the title, description and
+// site name on the left and the lead image on the right. The images that can't be downloaded
+// or decoded are left out.
+func Render(ctx context.Context, preview goscraper.DocumentPreview, opts *Options) (*image.RGBA, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	width, height := opts.Width, opts.Height
+	if width <= 0 || height <= 0 {
+		width, height = defaultWidth, defaultHeight
+	}
+	scale := float64(width) / defaultWidth
+	px := func(v float64) int { return int(v*scale + 0.5) }
+
+	brand, ok := ParseColor(preview.ThemeColor)
+	if !ok {
+		brand = opts.Color
+		if brand == nil {
+			brand = defaultColor
+		}
+	}
+	card := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(card, card.Bounds(), image.White, image.Point{}, draw.Src)
+	bar := px(16)
+	draw.Draw(card, image.Rect(0, 0, width, bar), image.NewUniform(brand), image.Point{}, draw.Src)
+
+	textRight := width
+	if len(preview.Images) > 0 {
+		if lead := opts.download(ctx, preview.Images[0]); lead != nil {
+			area := image.Rect(width*58/100, bar, width, height)
+			cover(card, area, lead)
+			textRight = area.Min.X
+		}
+	}
+
+	faces, err := loadFaces(scale)
+	if err != nil {
+		return nil, err
+	}
+	defer faces.close()
+	pad := px(60)
+	textWidth := textRight - 2*pad
+	y := bar + pad
+	for _, line := range wrap(faces.title, strings.TrimSpace(preview.Title), textWidth, 3) {
+		y += faces.title.Metrics().Height.Ceil()
+		drawText(card, faces.title, textColor, pad, y, line)
+	}
+	y += px(24)
+	footer := height - pad - px(40)
+	lineHeight := faces.text.Metrics().Height.Ceil()
+	if lines := (footer - px(16) - y) / (lineHeight + px(6)); lines > 0 {
+		for _, line := range wrap(faces.text, strings.TrimSpace(preview.Description), textWidth, lines) {
+			y += lineHeight + px(6)
+			drawText(card, faces.text, mutedColor, pad, y, line)
+		}
+	}
+
+	x := pad
+	if len(preview.Icon) > 0 {
+		if icon := opts.download(ctx, preview.Icon); icon != nil {
+			draw.CatmullRom.Scale(card, image.Rect(pad, footer, pad+px(40), footer+px(40)), icon, icon.Bounds(), draw.Over, nil)
+			x += px(56)
+		}
+	}
+	drawText(card, faces.name, brand, x, footer+px(30), truncated(faces.name, preview.Name, textRight-pad-x))
+	return card, nil
+}
+
+func (opts *Options) download(ctx context.Context, u string) image.Image {
+	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil
+	}
+	fetcher := opts.Fetcher
+	if fetcher == nil {
+		fetcher = http.DefaultClient
+	}
+	resp, err := fetcher.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil
+	}
+	img, _, err := image.Decode(io.LimitReader(resp.Body, maxImageSize))
+	if err != nil {
+		return nil
+	}
+	return img
+}
+
+func cover(dst draw.Image, area image.Rectangle, img image.Image) {
+	src := img.Bounds()
+	if src.Dx() == 0 || src.Dy() == 0 {
+		return
+	}
+	// crop the source to the aspect ratio of the area
+	if src.Dx()*area.Dy() > src.Dy()*area.Dx() {
+		w := src.Dy() * area.Dx() / area.Dy()
+		src.Min.X += (src.Dx() - w) / 2
+		src.Max.X = src.Min.X + w
+	} else {
+		h := src.Dx() * area.Dy() / area.Dx()
+		src.Min.Y += (src.Dy() - h) / 2
+		src.Max.Y = src.Min.Y + h
+	}
+	draw.CatmullRom.Scale(dst, area, img, src, draw.Src, nil)
+}
+
+type faces struct {
+	title, text, name font.Face
+}
+
+func (f *faces) close() {
+	f.title.Close()
+	f.text.Close()
+	f.name.Close()
+}
+
+var (
+	parseFonts    sync.Once
+	regular, bold *opentype.Font
+	fontErr       error
+)
+
+func loadFaces(scale float64) (*faces, error) {
+	parseFonts.Do(func() {
+		if regular, fontErr = opentype.Parse(goregular.TTF); fontErr != nil {
+			return
+		}
+		bold, fontErr = opentype.Parse(gobold.TTF)
+	})
+	if fontErr != nil {
+		return nil, fontErr
+	}
+	face := func(f *opentype.Font, size float64) (font.Face, error) {
+		return opentype.NewFace(f, &opentype.FaceOptions{Size: size * scale, DPI: 72, Hinting: font.HintingFull})
+	}
+	var err error
+	fs := &faces{}
+	if fs.title, err = face(bold, 56); err != nil {
+		return nil, err
+	}
+	if fs.text, err = face(regular, 30); err != nil {
+		return nil, err
+	}
+	if fs.name, err = face(bold, 28); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+func drawText(dst draw.Image, face font.Face, c color.Color, x, y int, s string) {
+	d := font.Drawer{Dst: dst, Src: image.NewUniform(c), Face: face, Dot: fixed.P(x, y)}
+	d.DrawString(s)
+}
+
+func wrap(face font.Face, s string, width, n int) []string {
+	var lines []string
+	words := strings.Fields(s)
+	for len(words) > 0 && len(lines) < n {
+		line := words[0]
+		words = words[1:]
+		for len(words) > 0 && font.MeasureString(face, line+" "+words[0]).Ceil() <= width {
+			line += " " + words[0]
+			words = words[1:]
+		}
+		if len(lines) == n-1 && len(words) > 0 {
+			line += " " + strings.Join(words, " ")
+		}
+		lines = append(lines, truncated(face, line, width))
+	}
+	return lines
+}
+
+func truncated(face font.Face, s string, width int) string {
+	if font.MeasureString(face, s).Ceil() <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && font.MeasureString(face, string(runes)+"…").Ceil() > width {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRight(string(runes), " ,.;:") + "…"
+}
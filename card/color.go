@@ -0,0 +1,63 @@
+package card
+
+import (
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+var namedColors = map[string]color.RGBA{
+	"black":  {0x00, 0x00, 0x00, 0xff},
+	"white":  {0xff, 0xff, 0xff, 0xff},
+	"red":    {0xff, 0x00, 0x00, 0xff},
+	"green":  {0x00, 0x80, 0x00, 0xff},
+	"blue":   {0x00, 0x00, 0xff, 0xff},
+	"navy":   {0x00, 0x00, 0x80, 0xff},
+	"orange": {0xff, 0xa5, 0x00, 0xff},
+	"purple": {0x80, 0x00, 0x80, 0xff},
+	"teal":   {0x00, 0x80, 0x80, 0xff},
+	"gray":   {0x80, 0x80, 0x80, 0xff},
+	"grey":   {0x80, 0x80, 0x80, 0xff},
+}
+
+// ParseColor parses a css color as found in theme-color meta tags: #rgb, #rrggbb, #rrggbbaa,
+// rgb(r, g, b) or a common color name
+func ParseColor(s string) (color.Color, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c, true
+	}
+	if strings.HasPrefix(s, "#") {
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) == 6 {
+			hex += "ff"
+		}
+		if len(hex) != 8 {
+			return nil, false
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return nil, false
+		}
+		return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, true
+	}
+	if strings.HasPrefix(s, "rgb(") && strings.HasSuffix(s, ")") {
+		parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(s, "rgb("), ")"), ",")
+		if len(parts) != 3 {
+			return nil, false
+		}
+		var rgb [3]uint8
+		for i, part := range parts {
+			v, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || v < 0 || v > 255 {
+				return nil, false
+			}
+			rgb[i] = uint8(v)
+		}
+		return color.RGBA{rgb[0], rgb[1], rgb[2], 0xff}, true
+	}
+	return nil, false
+}
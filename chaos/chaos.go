@@ -0,0 +1,159 @@
+// Package chaos injects faults in the responses of a goscraper.Fetcher, to load test the
+// applications built on goscraper and check how they cope with misbehaving origins.
+//
+//	f := &chaos.Fetcher{Latency: time.Second, ErrorRate: 0.1, TruncateRate: 0.05}
+//	s := &goscraper.Scraper{Url: u, MaxRedirect: 5, Fetcher: f}
+package chaos
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/badoux/goscraper"
+)
+
+var errorStatuses = []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// Fetcher sends the requests with another Fetcher and degrades their responses at random.
+// The rates are probabilities between 0 and 1, drawn independently for each request.
+type Fetcher struct {
+	// Fetcher sends the requests, http.DefaultClient by default. The redirect settings of the
+	// scraper only apply to the client it builds itself, not to this one.
+	Fetcher goscraper.Fetcher
+	// Latency is waited for before each request, plus a random delay of up to Jitter
+	Latency time.Duration
+	Jitter  time.Duration
+	// ErrorRate is the rate of requests answered with a 500, 502, 503 or 504 without being sent
+	ErrorRate float64
+	// TruncateRate is the rate of bodies cut at a random length, their reads then failing
+	// with io.ErrUnexpectedEOF as when the connection is lost
+	TruncateRate float64
+	// CharsetRate is the rate of responses whose Content-Type announces an unknown charset
+	CharsetRate float64
+	// Seed seeds the draws for reproducible runs, the current time is used when 0
+	Seed int64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+func (f *Fetcher) Do(req *http.Request) (*http.Response, error) {
+	if err := f.wait(req.Context()); err != nil {
+		return nil, err
+	}
+	if f.draw(f.ErrorRate) {
+		code := errorStatuses[f.intn(len(errorStatuses))]
+		return &http.Response{
+			Status:        http.StatusText(code),
+			StatusCode:    code,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"text/plain; charset=utf-8"}},
+			Body:          io.NopCloser(strings.NewReader(http.StatusText(code))),
+			ContentLength: int64(len(http.StatusText(code))),
+			Request:       req,
+		}, nil
+	}
+
+	fetcher := f.Fetcher
+	if fetcher == nil {
+		fetcher = http.DefaultClient
+	}
+	resp, err := fetcher.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if f.draw(f.CharsetRate) {
+		malformCharset(resp)
+	}
+	if f.draw(f.TruncateRate) {
+		// bodies of unknown length are cut in their first 32KB
+		n := int64(32 << 10)
+		if resp.ContentLength > 0 {
+			n = resp.ContentLength
+		}
+		resp.Body = &truncatedBody{ReadCloser: resp.Body, n: int64(f.intn(int(n)))}
+	}
+	return resp, nil
+}
+
+func (f *Fetcher) wait(ctx context.Context) error {
+	d := f.Latency
+	if f.Jitter > 0 {
+		d += time.Duration(f.intn(int(f.Jitter)))
+	}
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+func (f *Fetcher) draw(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.random().Float64() < rate
+}
+
+func (f *Fetcher) intn(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.random().Intn(n)
+}
+
+// random returns the source of the draws, f.mu must be held
+func (f *Fetcher) random() *rand.Rand {
+	if f.rand == nil {
+		seed := f.Seed
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		f.rand = rand.New(rand.NewSource(seed))
+	}
+	return f.rand
+}
+
+func malformCharset(resp *http.Response) {
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType, params = "text/html", map[string]string{}
+	}
+	params["charset"] = "x-chaos-unknown"
+	resp.Header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+}
+
+type truncatedBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.n <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if int64(len(p)) > b.n {
+		p = p[:b.n]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.n -= int64(n)
+	return n, err
+}
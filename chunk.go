@@ -0,0 +1,88 @@
+package goscraper
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunk is a section of the text of the main content, see Scraper.ChunkSize
+type Chunk struct {
+	// Heading is the heading of the section the chunk is in, if any, and Level its level
+	Heading string `json:"heading,omitempty"`
+	Level   int    `json:"level,omitempty"`
+	Text    string `json:"text"`
+	// Start and End are the offsets of the chunk in Content.Text, in characters (runes)
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// chunks joins blocks into the text of the main content and cuts it into chunks of at most
+// size runes, no chunks when size isn't set. A new chunk starts at each heading, paragraphs
+// longer than size are cut at sentence or word boundaries.
+func chunks(blocks []textBlock, size int) (string, []Chunk) {
+	var text strings.Builder
+	var chunks []Chunk
+	var current *Chunk
+	var heading string
+	var level int
+	// offset is the offset of the end of text in runes
+	offset := 0
+	add := func(s string, start int) {
+		chunks = append(chunks, Chunk{Heading: heading, Level: level, Text: s, Start: start, End: start + utf8.RuneCountInString(s)})
+		current = &chunks[len(chunks)-1]
+	}
+	for i, block := range blocks {
+		if i > 0 {
+			text.WriteString("\n\n")
+			offset += 2
+		}
+		text.WriteString(block.text)
+		start := offset
+		offset += utf8.RuneCountInString(block.text)
+		if size <= 0 {
+			continue
+		}
+		if block.heading > 0 {
+			heading, level = block.text, block.heading
+			current = nil
+		}
+		n := offset - start
+		switch {
+		case current != nil && current.End-current.Start+2+n <= size:
+			current.Text += "\n\n" + block.text
+			current.End = offset
+		case n <= size:
+			add(block.text, start)
+		default:
+			for _, piece := range splitRunes(block.text, size) {
+				add(piece, start)
+				start += utf8.RuneCountInString(piece)
+			}
+			// the pieces of a long paragraph aren't joined with the next blocks
+			current = nil
+		}
+	}
+	return text.String(), chunks
+}
+
+// splitRunes cuts s into pieces of at most size runes, after the last sentence end or else
+// the last space of each piece when there is one. The pieces joined give back s.
+func splitRunes(s string, size int) []string {
+	var pieces []string
+	for utf8.RuneCountInString(s) > size {
+		head := string([]rune(s)[:size])
+		cut := -1
+		for _, end := range []string{". ", "! ", "? ", "; ", " "} {
+			if i := strings.LastIndex(head, end); i > 0 {
+				cut = i + len(end)
+				break
+			}
+		}
+		if cut <= 0 {
+			cut = len(head)
+		}
+		pieces = append(pieces, s[:cut])
+		s = s[cut:]
+	}
+	return append(pieces, s)
+}
@@ -0,0 +1,97 @@
+package goscraper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChunks(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks []textBlock
+		size   int
+		text   string
+		chunks []Chunk
+	}{
+		{
+			name:   "no size",
+			blocks: []textBlock{{text: "Title", heading: 1}, {text: "Body"}},
+			text:   "Title\n\nBody",
+		},
+		{
+			name:   "blocks joined",
+			blocks: []textBlock{{text: "Intro", heading: 1}, {text: "one"}, {text: "two"}},
+			size:   100,
+			text:   "Intro\n\none\n\ntwo",
+			chunks: []Chunk{{Heading: "Intro", Level: 1, Text: "Intro\n\none\n\ntwo", Start: 0, End: 15}},
+		},
+		{
+			name:   "new chunk at headings",
+			blocks: []textBlock{{text: "A", heading: 1}, {text: "x"}, {text: "B", heading: 2}, {text: "y"}},
+			size:   100,
+			text:   "A\n\nx\n\nB\n\ny",
+			chunks: []Chunk{
+				{Heading: "A", Level: 1, Text: "A\n\nx", Start: 0, End: 4},
+				{Heading: "B", Level: 2, Text: "B\n\ny", Start: 6, End: 10},
+			},
+		},
+		{
+			name:   "size reached",
+			blocks: []textBlock{{text: "aaaa"}, {text: "bbbb"}},
+			size:   8,
+			text:   "aaaa\n\nbbbb",
+			chunks: []Chunk{{Text: "aaaa", Start: 0, End: 4}, {Text: "bbbb", Start: 6, End: 10}},
+		},
+		{
+			name:   "long paragraph",
+			blocks: []textBlock{{text: "One two. Three four"}, {text: "end"}},
+			size:   10,
+			text:   "One two. Three four\n\nend",
+			chunks: []Chunk{
+				{Text: "One two. ", Start: 0, End: 9},
+				{Text: "Three four", Start: 9, End: 19},
+				{Text: "end", Start: 21, End: 24},
+			},
+		},
+		{
+			name:   "offsets in runes",
+			blocks: []textBlock{{text: "été"}, {text: "hiver"}},
+			size:   6,
+			text:   "été\n\nhiver",
+			chunks: []Chunk{{Text: "été", Start: 0, End: 3}, {Text: "hiver", Start: 5, End: 10}},
+		},
+	}
+	for _, tt := range tests {
+		text, chunks := chunks(tt.blocks, tt.size)
+		if text != tt.text {
+			t.Errorf("%s: text %q, want %q", tt.name, text, tt.text)
+		}
+		if !reflect.DeepEqual(chunks, tt.chunks) {
+			t.Errorf("%s: chunks %+v, want %+v", tt.name, chunks, tt.chunks)
+		}
+	}
+}
+
+func TestSplitRunes(t *testing.T) {
+	tests := []struct {
+		s    string
+		size int
+		want []string
+	}{
+		{"short", 10, []string{"short"}},
+		{"abcdefgh", 3, []string{"abc", "def", "gh"}},
+		{"One two. Three four", 10, []string{"One two. ", "Three four"}},
+		{"one two three", 9, []string{"one two ", "three"}},
+		{"héllo wörld", 6, []string{"héllo ", "wörld"}},
+	}
+	for _, tt := range tests {
+		got := splitRunes(tt.s, tt.size)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitRunes(%q, %d) = %q, want %q", tt.s, tt.size, got, tt.want)
+		}
+		if strings.Join(got, "") != tt.s {
+			t.Errorf("splitRunes(%q, %d) pieces don't join back", tt.s, tt.size)
+		}
+	}
+}
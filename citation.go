@@ -0,0 +1,78 @@
+package goscraper
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	doiRegexp   = regexp.MustCompile(`\b10\.\d{4,9}/[^\s"'<>]+`)
+	arxivRegexp = regexp.MustCompile(`arxiv\.org/(?:abs|pdf)/([a-z-]+/\d{7}|\d{4}\.\d{4,5})(?:v\d+)?`)
+)
+
+// Citation is the bibliographic data of a scholarly document, read from its Highwire Press
+// citation_* meta tags, Dublin Core identifiers or url
+type Citation struct {
+	Title   string   `json:"title,omitempty"`
+	Authors []string `json:"authors,omitempty"`
+	Journal string   `json:"journal,omitempty"`
+	Date    string   `json:"date,omitempty"`
+	DOI     string   `json:"doi,omitempty"`
+	ArXiv   string   `json:"arxiv,omitempty"`
+	// PDF is the url of the full text
+	PDF string `json:"pdf,omitempty"`
+}
+
+type citationExtractor struct{}
+
+func (e *citationExtractor) Token(page *Page, token html.Token) error {
+	return nil
+}
+
+func (e *citationExtractor) Finalize(page *Page) error {
+	meta := page.Doc.Meta
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if values := meta[key]; len(values) > 0 && len(strings.TrimSpace(values[0])) > 0 {
+				return strings.TrimSpace(values[0])
+			}
+		}
+		return ""
+	}
+	c := Citation{
+		Title:   first("citation_title"),
+		Journal: first("citation_journal_title", "citation_conference_title", "prism.publicationname"),
+		Date:    first("citation_publication_date", "citation_date", "citation_online_date"),
+		ArXiv:   first("citation_arxiv_id"),
+	}
+	for _, author := range meta["citation_author"] {
+		if author = strings.TrimSpace(author); len(author) > 0 {
+			c.Authors = append(c.Authors, author)
+		}
+	}
+	// the doi can be a bare identifier, a doi: uri or an https://doi.org url
+	for _, value := range append([]string{first("citation_doi", "prism.doi"), page.Url.String()}, append(meta["dc.identifier"], meta["dcterms.identifier"]...)...) {
+		if doi := doiRegexp.FindString(value); len(doi) > 0 {
+			c.DOI = doi
+			break
+		}
+	}
+	if len(c.ArXiv) == 0 {
+		if m := arxivRegexp.FindStringSubmatch(page.Url.String()); m != nil {
+			c.ArXiv = m[1]
+		}
+	}
+	if pdf := first("citation_pdf_url"); len(pdf) > 0 {
+		u, err := page.Resolve(pdf)
+		if err != nil {
+			return err
+		}
+		c.PDF = u
+	}
+	if len(c.Title) > 0 || len(c.Authors) > 0 || len(c.DOI) > 0 || len(c.ArXiv) > 0 || len(c.PDF) > 0 {
+		page.Doc.Preview.Citation = &c
+	}
+	return nil
+}
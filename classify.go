@@ -0,0 +1,55 @@
+package goscraper
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// URLClass tells how a url is scraped, see Scraper.Classifier
+type URLClass int
+
+const (
+	// ClassDefault urls are scraped as usual
+	ClassDefault URLClass = iota
+	// ClassSkip urls are not fetched, their scrape fails with ErrSkipped
+	ClassSkip
+	// ClassLowPriority urls are scraped as usual, batch runners can call Scraper.Classify to
+	// scrape them last
+	ClassLowPriority
+)
+
+// Classification is the verdict of a Classifier on a url
+type Classification struct {
+	Class URLClass
+	// Handler, if set, builds the document of the url instead of the scraper, e.g. with the
+	// API of a known media host
+	Handler func(ctx context.Context, u *url.URL) (*Document, error)
+	// Reason is reported in the ErrSkipped of skipped urls
+	Reason string
+}
+
+// Classify returns the classification of the scraper's url by its Classifier
+func (scraper *Scraper) Classify() Classification {
+	if scraper.Classifier == nil || scraper.Url == nil {
+		return Classification{}
+	}
+	return scraper.Classifier(scraper.Url)
+}
+
+// classified scrapes the url of the scraper as its classification says, done is false
+// when the scraper has to fetch it
+func (scraper *Scraper) classified(ctx context.Context) (doc *Document, done bool, err error) {
+	c := scraper.Classify()
+	switch {
+	case c.Class == ClassSkip:
+		if len(c.Reason) > 0 {
+			return nil, true, fmt.Errorf("%w: %s: %s", ErrSkipped, scraper.Url, c.Reason)
+		}
+		return nil, true, fmt.Errorf("%w: %s", ErrSkipped, scraper.Url)
+	case c.Handler != nil:
+		doc, err := c.Handler(ctx, scraper.Url)
+		return doc, true, err
+	}
+	return nil, false, nil
+}
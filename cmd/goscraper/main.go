@@ -0,0 +1,200 @@
+// Command goscraper prints the preview of web pages.
+//
+// Usage:
+//
+//	goscraper [flags] [url ...]
+//
+// Urls are read from the arguments, from the file given by -f, or from the
+// standard input when there are none, one per line.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/badoux/goscraper"
+)
+
+type result struct {
+	Url     string                     `json:"url"`
+	Error   string                     `json:"error,omitempty"`
+	Preview *goscraper.DocumentPreview `json:"preview,omitempty"`
+	// Warnings are the problems that degraded the preview without failing the scrape
+	Warnings []goscraper.Warning `json:"warnings,omitempty"`
+}
+
+func main() {
+	userAgent := flag.String("user-agent", "", "User-Agent sent with the requests (default GoScraper)")
+	maxLength := flag.Int64("max-length", 0, "maximum document length in bytes, 0 for no limit")
+	maxRedirect := flag.Int("max-redirect", 5, "maximum number of redirects")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of each scrape")
+	concurrency := flag.Int("concurrency", 4, "number of pages scraped at the same time")
+	maxMemory := flag.Int64("max-memory", 0, "memory in bytes the documents being scraped may hold, 0 for no limit")
+	file := flag.String("f", "", "file listing the urls to scrape, - for the standard input")
+	format := flag.String("format", "json", "output format, json (one preview per line) or table")
+	blocklist := flag.String("blocklist", "", "file or url of a list of hosts not to scrape, e.g. a hosts file")
+	expand := flag.Bool("expand", false, "only follow the redirects of the urls, the previews then only hold the final url")
+	flag.Parse()
+
+	if *format != "json" && *format != "table" {
+		fmt.Fprintf(os.Stderr, "goscraper: unknown format %q\n", *format)
+		os.Exit(2)
+	}
+	urls, err := readUrls(flag.Args(), *file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "goscraper:", err)
+		os.Exit(1)
+	}
+	if *concurrency < 1 {
+		*concurrency = 1
+	}
+
+	var budget *goscraper.MemoryBudget
+	if *maxMemory > 0 {
+		budget = goscraper.NewMemoryBudget(*maxMemory)
+	}
+
+	var blocked *goscraper.Blocklist
+	if len(*blocklist) > 0 {
+		source := goscraper.BlocklistFile(*blocklist)
+		if strings.HasPrefix(*blocklist, "http://") || strings.HasPrefix(*blocklist, "https://") {
+			source = goscraper.BlocklistURL(*blocklist)
+		}
+		blocked = goscraper.NewBlocklist(0, source)
+		if err := blocked.Load(context.Background()); err != nil {
+			fmt.Fprintln(os.Stderr, "goscraper:", err)
+			os.Exit(1)
+		}
+	}
+
+	scrape := func(uri string) result {
+		r := result{Url: uri}
+		u, err := url.Parse(uri)
+		if err != nil {
+			r.Error = err.Error()
+			return r
+		}
+		scraper := &goscraper.Scraper{
+			Url:               u,
+			MaxRedirect:       *maxRedirect,
+			MaxDocumentLength: *maxLength,
+			UserAgent:         *userAgent,
+			Timeout:           *timeout,
+			MemoryBudget:      budget,
+			Blocklist:         blocked,
+			ExpandOnly:        *expand,
+		}
+		doc, err := scraper.ScrapeContext(context.Background())
+		if err != nil {
+			r.Error = err.Error()
+			return r
+		}
+		r.Preview = &doc.Preview
+		r.Warnings = doc.Warnings
+		return r
+	}
+
+	// scrape concurrently but print the results in the order of the urls
+	results := make([]chan result, len(urls))
+	for i := range results {
+		results[i] = make(chan result, 1)
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < *concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] <- scrape(urls[i])
+			}
+		}()
+	}
+	go func() {
+		for i := range urls {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+
+	failed := false
+	if *format == "table" {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "URL\tTITLE\tDESCRIPTION\tIMAGE")
+		for _, c := range results {
+			r := <-c
+			if len(r.Error) > 0 {
+				failed = true
+				fmt.Fprintf(w, "%s\terror: %s\t\t\n", r.Url, cell(r.Error, 60))
+				continue
+			}
+			var image string
+			if len(r.Preview.Images) > 0 {
+				image = r.Preview.Images[0]
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Url, cell(r.Preview.Title, 40), cell(r.Preview.Description, 60), image)
+			for _, warning := range r.Warnings {
+				fmt.Fprintln(os.Stderr, "goscraper: warning:", warning)
+			}
+		}
+		w.Flush()
+	} else {
+		enc := json.NewEncoder(os.Stdout)
+		for _, c := range results {
+			r := <-c
+			if len(r.Error) > 0 {
+				failed = true
+			}
+			if err := enc.Encode(r); err != nil {
+				fmt.Fprintln(os.Stderr, "goscraper:", err)
+				os.Exit(1)
+			}
+		}
+	}
+	wg.Wait()
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func readUrls(args []string, file string) ([]string, error) {
+	if len(args) > 0 && len(file) == 0 {
+		return args, nil
+	}
+	var r io.Reader = os.Stdin
+	if len(file) > 0 && file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	urls := append([]string(nil), args...)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) > 0 && !strings.HasPrefix(line, "#") {
+			urls = append(urls, line)
+		}
+	}
+	return urls, scanner.Err()
+}
+
+func cell(s string, max int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > max {
+		return string(r[:max-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,121 @@
+package goscraper
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const maxCodeSample = 8 << 10
+
+var (
+	// codeLangRegexp matches the language classes of the highlighters, e.g. language-go,
+	// lang-go, highlight-source-go or brush: go
+	codeLangRegexp = regexp.MustCompile(`(?i)(?:^|\s)(?:(?:language|lang|highlight-source)-|brush:\s*)([a-z0-9_+#-]+)`)
+	// codeFenceRegexp matches a markdown fence opening a code block
+	codeFenceRegexp = regexp.MustCompile("^\\s*(```|~~~)([a-zA-Z0-9_+#-]*)[^\n]*\n")
+)
+
+// CodeSample is the first code block of a document
+type CodeSample struct {
+	// Language is the language hinted by the highlighter classes or the markdown fence, if any
+	Language string `json:"language,omitempty"`
+	Code     string `json:"code"`
+}
+
+type codeExtractor struct {
+	inPre    int
+	language string
+	// hint is the language of the last element with a language class, e.g. the wrapper
+	// of a GitHub gist
+	hint string
+	code strings.Builder
+	done bool
+}
+
+func (e *codeExtractor) Token(page *Page, token html.Token) error {
+	if e.done {
+		return nil
+	}
+	switch token.Type {
+	case html.StartTagToken:
+		if token.Data != "pre" && (e.inPre == 0 || token.Data != "code") {
+			if e.inPre > 0 {
+				return nil
+			}
+			for _, attr := range token.Attr {
+				if m := codeLangRegexp.FindStringSubmatch(attr.Val); m != nil && attr.Key == "class" {
+					e.hint = strings.ToLower(m[1])
+				}
+			}
+			return nil
+		}
+		if token.Data == "pre" {
+			e.inPre++
+		}
+		for _, attr := range token.Attr {
+			if len(e.language) > 0 {
+				break
+			}
+			switch attr.Key {
+			case "class":
+				if m := codeLangRegexp.FindStringSubmatch(attr.Val); m != nil {
+					e.language = strings.ToLower(m[1])
+				}
+			case "data-lang", "data-language":
+				e.language = cleanStr(attr.Val)
+			}
+		}
+	case html.TextToken:
+		if e.inPre > 0 && e.code.Len() <= maxCodeSample {
+			e.code.WriteString(token.Data)
+		}
+	case html.EndTagToken:
+		if token.Data != "pre" || e.inPre == 0 {
+			return nil
+		}
+		e.inPre--
+		if e.inPre > 0 {
+			return nil
+		}
+		code, language := fencedCode(e.code.String())
+		if len(e.language) == 0 {
+			e.language = language
+		}
+		if len(e.language) == 0 {
+			e.language = e.hint
+		}
+		if len(strings.TrimSpace(code)) > 0 {
+			page.Doc.Preview.CodeSample = &CodeSample{Language: e.language, Code: code}
+			e.done = true
+		}
+		e.language = ""
+		e.code.Reset()
+	}
+	return nil
+}
+
+func (e *codeExtractor) Finalize(page *Page) error {
+	return nil
+}
+
+func fencedCode(code string) (string, string) {
+	// the newline following <pre> isn't part of the content
+	code = strings.TrimPrefix(strings.TrimPrefix(code, "\r"), "\n")
+	var language string
+	if m := codeFenceRegexp.FindStringSubmatch(code); m != nil {
+		language = strings.ToLower(m[2])
+		code = code[len(m[0]):]
+		if i := strings.LastIndex(code, m[1]); i >= 0 {
+			code = code[:i]
+		}
+	}
+	if len(code) > maxCodeSample {
+		code = code[:maxCodeSample]
+		if i := strings.LastIndexByte(code, '\n'); i > 0 {
+			code = code[:i+1]
+		}
+	}
+	return strings.TrimRight(code, " \t\r\n"), language
+}
@@ -0,0 +1,78 @@
+package goscraper
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+var defaultEncodings = []string{"gzip", "deflate", "br"}
+
+func (scraper *Scraper) acceptEncoding() string {
+	if len(scraper.AcceptEncodings) == 0 {
+		return strings.Join(defaultEncodings, ", ")
+	}
+	return strings.Join(scraper.AcceptEncodings, ", ")
+}
+
+func decompress(resp *http.Response) {
+	encoding := cleanStr(resp.Header.Get("Content-Encoding"))
+	switch encoding {
+	case "gzip", "x-gzip", "deflate", "br":
+	default:
+		return
+	}
+	resp.Body = &decodedBody{body: resp.Body, encoding: encoding}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// decodedBody decodes a body on the first read, so that a corrupt body fails the read
+type decodedBody struct {
+	body     io.ReadCloser
+	encoding string
+	r        io.Reader
+	err      error
+}
+
+func (b *decodedBody) Read(p []byte) (int, error) {
+	if b.r == nil && b.err == nil {
+		b.r, b.err = b.decoder()
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.r.Read(p)
+}
+
+func (b *decodedBody) decoder() (io.Reader, error) {
+	switch b.encoding {
+	case "br":
+		return brotli.NewReader(b.body), nil
+	case "deflate":
+		// deflate should be zlib wrapped, some servers send raw deflate
+		r := bufio.NewReader(b.body)
+		header, err := r.Peek(2)
+		if err != nil {
+			return nil, err
+		}
+		if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+			return zlib.NewReader(r)
+		}
+		return flate.NewReader(r), nil
+	default:
+		return gzip.NewReader(b.body)
+	}
+}
+
+func (b *decodedBody) Close() error {
+	return b.body.Close()
+}
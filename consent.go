@@ -0,0 +1,43 @@
+package goscraper
+
+import (
+	"context"
+	"net/url"
+)
+
+// Consent decides whether urls may be scraped, e.g. refusing the hosts of a region or the
+// pages of a category of personal data for some tenants. It's called with the url of the
+// document before it's fetched and with the urls of its redirects, tenant is nil for the
+// scrapers without Tenant. Its errors fail the scrape.
+//
+// The redirects are checked by the http.Client of the scraper. A custom Scraper.Fetcher
+// following redirects itself, e.g. a browser, bypasses the check: only the url asked for is checked.
+type Consent interface {
+	// Check returns the reason to deny scraping u, empty to allow it
+	Check(ctx context.Context, u *url.URL, tenant *Tenant) (reason string, err error)
+}
+
+// ConsentFunc adapts a function to the Consent interface
+type ConsentFunc func(ctx context.Context, u *url.URL, tenant *Tenant) (string, error)
+
+func (f ConsentFunc) Check(ctx context.Context, u *url.URL, tenant *Tenant) (string, error) {
+	return f(ctx, u, tenant)
+}
+
+func (scraper *Scraper) checkConsent(ctx context.Context, u *url.URL) error {
+	if scraper.Consent == nil {
+		return nil
+	}
+	reason, err := scraper.Consent.Check(ctx, u, scraper.Tenant)
+	if err != nil {
+		return err
+	}
+	if len(reason) > 0 {
+		denied := ErrDenied{Url: u.String(), Reason: reason}
+		if scraper.Tenant != nil {
+			denied.Tenant = scraper.Tenant.Name
+		}
+		return denied
+	}
+	return nil
+}
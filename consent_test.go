@@ -0,0 +1,54 @@
+package goscraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeniedRedirectNotRetried(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/private" {
+			io.WriteString(w, testPage)
+			return
+		}
+		requests.Add(1)
+		http.Redirect(w, r, "/private", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL + "/")
+	breaker := NewCircuitBreaker(1, time.Minute)
+	metrics := &Metrics{}
+	scraper := &Scraper{
+		Url: u, MaxRedirect: 5, Retries: 2, Backoff: time.Millisecond, Breaker: breaker, Metrics: metrics,
+		Consent: ConsentFunc(func(ctx context.Context, u *url.URL, tenant *Tenant) (string, error) {
+			if strings.HasPrefix(u.Path, "/private") {
+				return "personal data", nil
+			}
+			return "", nil
+		}),
+	}
+	_, err := scraper.Scrape()
+	var denied ErrDenied
+	if !errors.As(err, &denied) || denied.Reason != "personal data" {
+		t.Fatalf("got error %v, want ErrDenied", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+	if err := breaker.allow(u.Host); err != nil {
+		t.Errorf("denied redirect opened the circuit: %v", err)
+	}
+	if n := metrics.Snapshot().Failures[FailureDenied.String()]; n != 1 {
+		t.Errorf("got %d denied failures, want 1", n)
+	}
+}
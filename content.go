@@ -0,0 +1,101 @@
+package goscraper
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// defaultFallbackLength is the length of the descriptions taken from the body when
+	// Scraper.FallbackLength isn't set
+	defaultFallbackLength = 300
+	// minParagraph is the length from which a paragraph is worth describing the page
+	minParagraph = 40
+)
+
+var skippedElements = map[string]bool{
+	"nav": true, "header": true, "footer": true, "aside": true, "form": true,
+	"script": true, "style": true, "noscript": true, "template": true, "button": true,
+}
+
+type contentExtractor struct {
+	length int
+
+	skipped   int
+	inH1      bool
+	inP       bool
+	h1        string
+	paragraph strings.Builder
+	paras     []string
+	size      int
+}
+
+func (e *contentExtractor) Token(page *Page, token html.Token) error {
+	switch token.Type {
+	case html.StartTagToken:
+		if skippedElements[token.Data] {
+			e.skipped++
+		}
+		switch token.Data {
+		case "h1":
+			e.inH1 = len(e.h1) == 0
+		case "p":
+			e.inP = e.size < e.length
+			e.paragraph.Reset()
+		}
+	case html.EndTagToken:
+		if skippedElements[token.Data] && e.skipped > 0 {
+			e.skipped--
+		}
+		switch token.Data {
+		case "h1":
+			e.inH1 = false
+		case "p":
+			if e.inP {
+				p := strings.Join(strings.Fields(e.paragraph.String()), " ")
+				if utf8.RuneCountInString(p) >= minParagraph {
+					e.paras = append(e.paras, p)
+					e.size += utf8.RuneCountInString(p) + 1
+				}
+			}
+			e.inP = false
+		}
+	case html.TextToken:
+		if e.skipped > 0 {
+			return nil
+		}
+		if e.inH1 {
+			e.h1 += token.Data
+		}
+		if e.inP {
+			e.paragraph.WriteString(token.Data)
+			e.paragraph.WriteByte(' ')
+		}
+	}
+	return nil
+}
+
+func (e *contentExtractor) Finalize(page *Page) error {
+	preview := &page.Doc.Preview
+	if len(strings.TrimSpace(preview.Title)) == 0 {
+		preview.Title = strings.Join(strings.Fields(e.h1), " ")
+	}
+	if len(strings.TrimSpace(preview.Description)) == 0 {
+		preview.Description = truncate(strings.Join(e.paras, " "), e.length)
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	cut := []rune(s)[:n]
+	s = string(cut)
+	if i := strings.LastIndexByte(s, ' '); i > n/2 {
+		s = s[:i]
+	}
+	return strings.TrimRight(s, " ,.;:") + "…"
+}
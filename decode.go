@@ -0,0 +1,64 @@
+package goscraper
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"mime"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// utf8Reader decodes an html document to UTF-8. The encoding is determined from the
+// BOM, the Content-Type charset and the <meta charset> of the first 1024 bytes. A document
+// announced as UTF-8 that isn't is decoded by its <meta> instead, and a document in an
+// encoding that can't be decoded is read as is.
+// r must be a reader with a buffer of at least 1024 bytes. fallback describes how the
+// document was decoded when its declared charset couldn't be used.
+func utf8Reader(r *bufio.Reader, contentType string) (_ io.Reader, fallback string, err error) {
+	var src io.Reader = r
+	preview, err := r.Peek(1024)
+	if err == io.ErrUnexpectedEOF {
+		// the body is cut, decode what was received and fail at its end
+		src = io.MultiReader(r, cutReader{})
+	} else if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, "", err
+	}
+	e, name, _ := charset.DetermineEncoding(preview, contentType)
+	if _, params, _ := mime.ParseMediaType(contentType); len(params["charset"]) > 0 {
+		if declared, _ := charset.Lookup(params["charset"]); declared == nil {
+			fallback = fmt.Sprintf("unknown charset %q, decoded as %s", params["charset"], name)
+		}
+	}
+	if name == "utf-8" && !validUTF8Prefix(preview) {
+		// wrong header, sniff without it
+		e, name, _ = charset.DetermineEncoding(preview, "text/html")
+		fallback = "not utf-8 as declared, decoded as " + name
+	}
+	if e == nil || name == "replacement" {
+		return src, "charset " + name + " can't be decoded, read as is", nil
+	}
+	if name == "utf-8" {
+		return src, fallback, nil
+	}
+	return e.NewDecoder().Reader(src), fallback, nil
+}
+
+type cutReader struct{}
+
+func (cutReader) Read(p []byte) (int, error) {
+	return 0, io.ErrUnexpectedEOF
+}
+
+func validUTF8Prefix(b []byte) bool {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				b = b[:i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(b)
+}
@@ -0,0 +1,81 @@
+package goscraper
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	ErrTooLarge               = errors.New("goscraper: document too large")
+	ErrTooManyRedirects       = errors.New("goscraper: too many redirects")
+	ErrUnsupportedContentType = errors.New("goscraper: unsupported content type")
+	ErrCircuitOpen            = errors.New("goscraper: circuit open")
+	ErrDisallowed             = errors.New("goscraper: disallowed")
+	ErrRedirectRefused        = errors.New("goscraper: redirect refused")
+	ErrCanonicalLoop          = errors.New("goscraper: canonical loop")
+	ErrInvalidConfig          = errors.New("goscraper: invalid configuration")
+	ErrSkipped                = errors.New("goscraper: skipped")
+	ErrBlocked                = errors.New("goscraper: blocked host")
+	ErrUnsafe                 = errors.New("goscraper: unsafe url")
+)
+
+// ErrHTTPStatus is returned when the server answers with a non-2xx status code
+type ErrHTTPStatus struct {
+	Code int
+}
+
+func (e ErrHTTPStatus) Error() string {
+	return fmt.Sprintf("goscraper: unexpected http status %d %s", e.Code, http.StatusText(e.Code))
+}
+
+// ErrDenied is returned when Scraper.Consent denies scraping a url
+type ErrDenied struct {
+	Url    string
+	Reason string
+	// Tenant is the name of the tenant of the scraper, if any
+	Tenant string
+}
+
+func (e ErrDenied) Error() string {
+	if len(e.Tenant) > 0 {
+		return fmt.Sprintf("goscraper: denied for tenant %s: %s: %s", e.Tenant, e.Url, e.Reason)
+	}
+	return fmt.Sprintf("goscraper: denied: %s: %s", e.Url, e.Reason)
+}
+
+// ErrPanic is returned when a scrape panics, with the stack of the panic
+type ErrPanic struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e ErrPanic) Error() string {
+	return fmt.Sprintf("goscraper: panic: %v", e.Value)
+}
+
+// Unwrap returns the value of the panic if it's an error
+func (e ErrPanic) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+type maxLengthReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *maxLengthReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+	n, err := l.r.Read(p)
+	if int64(n) > l.n {
+		n = int(l.n)
+		l.n = 0
+		return n, ErrTooLarge
+	}
+	l.n -= int64(n)
+	return n, err
+}
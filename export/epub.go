@@ -0,0 +1,140 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+<rootfiles>
+<rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+</rootfiles>
+</container>
+`
+
+// WriteEPUB writes the bundle as an EPUB 3 book, a chapter by document
+func (b *Bundle) WriteEPUB(ctx context.Context, w io.Writer) error {
+	z := zip.NewWriter(w)
+	// the mimetype comes first, uncompressed, for readers to recognize the file
+	f, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(f, "application/epub+zip"); err != nil {
+		return err
+	}
+	if err := writeFile(z, "META-INF/container.xml", containerXML); err != nil {
+		return err
+	}
+
+	images := &imageSet{urls: map[string]*image{}}
+	for i, c := range b.chapters {
+		content := b.rewrite(ctx, c, images, func(img *image) string { return img.name })
+		page := fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="%s">
+<head><title>%s</title></head>
+<body>
+<h1>%s</h1>
+<p><a href="%s">%s</a></p>
+%s
+</body>
+</html>
+`, html.EscapeString(b.language()), html.EscapeString(c.title), html.EscapeString(c.title), html.EscapeString(c.link), html.EscapeString(c.link), content)
+		if err := writeFile(z, fmt.Sprintf("OEBPS/doc%d.xhtml", i+1), page); err != nil {
+			return err
+		}
+	}
+	for _, img := range images.images {
+		f, err := z.Create("OEBPS/" + img.name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(img.data); err != nil {
+			return err
+		}
+	}
+	if err := writeFile(z, "OEBPS/nav.xhtml", b.nav()); err != nil {
+		return err
+	}
+	if err := writeFile(z, "OEBPS/content.opf", b.packageDocument(images)); err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+func writeFile(z *zip.Writer, name, content string) error {
+	f, err := z.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(f, content)
+	return err
+}
+
+func (b *Bundle) nav() string {
+	var items strings.Builder
+	for i, c := range b.chapters {
+		fmt.Fprintf(&items, "<li><a href=\"doc%d.xhtml\">%s</a></li>\n", i+1, html.EscapeString(c.title))
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="%s">
+<head><title>%s</title></head>
+<body>
+<nav epub:type="toc" id="toc">
+<ol>
+%s</ol>
+</nav>
+</body>
+</html>
+`, html.EscapeString(b.language()), html.EscapeString(b.Title), items.String())
+}
+
+func (b *Bundle) packageDocument(images *imageSet) string {
+	var manifest, spine strings.Builder
+	manifest.WriteString("<item id=\"nav\" href=\"nav.xhtml\" media-type=\"application/xhtml+xml\" properties=\"nav\"/>\n")
+	for i := range b.chapters {
+		fmt.Fprintf(&manifest, "<item id=\"doc%d\" href=\"doc%d.xhtml\" media-type=\"application/xhtml+xml\"/>\n", i+1, i+1)
+		fmt.Fprintf(&spine, "<itemref idref=\"doc%d\"/>\n", i+1)
+	}
+	for i, img := range images.images {
+		fmt.Fprintf(&manifest, "<item id=\"img%d\" href=\"%s\" media-type=\"%s\"/>\n", i+1, img.name, img.mediaType)
+	}
+	return fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="id">
+<metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+<dc:identifier id="id">%s</dc:identifier>
+<dc:title>%s</dc:title>
+<dc:language>%s</dc:language>
+<meta property="dcterms:modified">%s</meta>
+</metadata>
+<manifest>
+%s</manifest>
+<spine>
+%s</spine>
+</package>
+`, b.identifier(), html.EscapeString(b.Title), html.EscapeString(b.language()), time.Now().UTC().Format("2006-01-02T15:04:05Z"), manifest.String(), spine.String())
+}
+
+// identifier identifies the book by the links of its documents, so that exporting the
+// same documents again updates the book in readers
+func (b *Bundle) identifier() string {
+	h := sha1.New()
+	for _, c := range b.chapters {
+		io.WriteString(h, c.link+"\n")
+	}
+	sum := h.Sum(nil)
+	// a version 5 uuid
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
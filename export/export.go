@@ -0,0 +1,223 @@
+// Package export packages the reader-mode content of scraped documents with their images
+// for offline reading, as an EPUB or a self-contained html file.
+//
+//	s := &goscraper.Scraper{Url: u, MaxRedirect: 5, MainContent: true}
+//	doc, err := s.Scrape()
+//	...
+//	b := export.New("Read later")
+//	b.Add(doc)
+//	err = b.WriteEPUB(ctx, w)
+package export
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/badoux/goscraper"
+	"golang.org/x/net/html"
+)
+
+const defaultMaxImageSize = 5 << 20
+
+var ErrNoContent = errors.New("export: document without main content")
+
+// Bundle is a set of documents exported together, in the order they're added
+type Bundle struct {
+	Title string
+	// Language is the language of the documents as a BCP 47 tag, en by default
+	Language string
+	// Fetcher downloads the images of the documents, http.DefaultClient by default
+	Fetcher goscraper.Fetcher
+	// MaxImageSize is the size of the largest image bundled, 5MB by default. The images
+	// larger than that, or failing to download, are left out.
+	MaxImageSize int64
+
+	chapters []chapter
+}
+
+type chapter struct {
+	title string
+	link  string
+	// content is the reader-mode html of the document
+	content string
+}
+
+type image struct {
+	name      string
+	mediaType string
+	data      []byte
+}
+
+type imageSet struct {
+	urls   map[string]*image
+	images []*image
+}
+
+// New returns an empty bundle titled title
+func New(title string) *Bundle {
+	return &Bundle{Title: title}
+}
+
+// Add adds the reader-mode content of doc to the bundle, doc must be scraped with
+// Scraper.MainContent
+func (b *Bundle) Add(doc *goscraper.Document) error {
+	if doc.Content == nil {
+		return fmt.Errorf("%w: %s", ErrNoContent, doc.Preview.Link)
+	}
+	title := strings.TrimSpace(doc.Preview.Title)
+	if len(title) == 0 {
+		title = doc.Preview.Link
+	}
+	b.chapters = append(b.chapters, chapter{title: title, link: doc.Preview.Link, content: doc.Content.Reader})
+	return nil
+}
+
+// Len returns the number of documents of the bundle
+func (b *Bundle) Len() int {
+	return len(b.chapters)
+}
+
+// WriteHTML writes the bundle as a single html file, its images inlined as data: urls
+func (b *Bundle) WriteHTML(ctx context.Context, w io.Writer) error {
+	images := &imageSet{urls: map[string]*image{}}
+	var body strings.Builder
+	if len(b.chapters) > 1 {
+		body.WriteString("<nav><ol>")
+		for i, c := range b.chapters {
+			fmt.Fprintf(&body, `<li><a href="#doc%d">%s</a></li>`, i+1, html.EscapeString(c.title))
+		}
+		body.WriteString("</ol></nav>\n")
+	}
+	for i, c := range b.chapters {
+		content := b.rewrite(ctx, c, images, func(img *image) string {
+			return "data:" + img.mediaType + ";base64," + base64.StdEncoding.EncodeToString(img.data)
+		})
+		fmt.Fprintf(&body, "<article id=\"doc%d\">\n<h1>%s</h1>\n<p><a href=\"%s\">%s</a></p>\n%s\n</article>\n",
+			i+1, html.EscapeString(c.title), html.EscapeString(c.link), html.EscapeString(c.link), content)
+	}
+	_, err := fmt.Fprintf(w, "<!DOCTYPE html>\n<html lang=\"%s\">\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n%s</body>\n</html>\n",
+		html.EscapeString(b.language()), html.EscapeString(b.Title), body.String())
+	return err
+}
+
+func (b *Bundle) language() string {
+	if len(b.Language) > 0 {
+		return b.Language
+	}
+	return "en"
+}
+
+func (b *Bundle) rewrite(ctx context.Context, c chapter, images *imageSet, src func(*image) string) string {
+	var out strings.Builder
+	t := html.NewTokenizer(strings.NewReader(c.content))
+	for {
+		if t.Next() == html.ErrorToken {
+			return out.String()
+		}
+		token := t.Token()
+		switch token.Type {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if token.Data == "img" {
+				img := b.image(ctx, c, attr(token, "src"), images)
+				if img == nil {
+					continue
+				}
+				for i := range token.Attr {
+					if token.Attr[i].Key == "src" {
+						token.Attr[i].Val = src(img)
+					}
+				}
+			}
+			if isVoid(token.Data) {
+				token.Type = html.SelfClosingTagToken
+			}
+		case html.EndTagToken:
+			if isVoid(token.Data) {
+				continue
+			}
+		case html.CommentToken, html.DoctypeToken:
+			continue
+		}
+		out.WriteString(token.String())
+	}
+}
+
+func (b *Bundle) image(ctx context.Context, c chapter, u string, images *imageSet) *image {
+	if img, ok := images.urls[u]; ok {
+		return img
+	}
+	images.urls[u] = nil
+	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return nil
+	}
+	// image hosts often refuse to serve other sites
+	req.Header.Set("Referer", c.link)
+	fetcher := b.Fetcher
+	if fetcher == nil {
+		fetcher = http.DefaultClient
+	}
+	resp, err := fetcher.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil
+	}
+	max := b.MaxImageSize
+	if max <= 0 {
+		max = defaultMaxImageSize
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, max+1))
+	if err != nil || int64(len(data)) > max {
+		return nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if !strings.HasPrefix(mediaType, "image/") {
+		mediaType = http.DetectContentType(data)
+	}
+	ext, ok := imageExtensions[mediaType]
+	if !ok {
+		return nil
+	}
+	img := &image{name: fmt.Sprintf("images/img%d%s", len(images.images)+1, ext), mediaType: mediaType, data: data}
+	images.urls[u] = img
+	images.images = append(images.images, img)
+	return img
+}
+
+// imageExtensions are the image types bundled, those of the core media types of EPUB
+var imageExtensions = map[string]string{
+	"image/jpeg":    ".jpg",
+	"image/png":     ".png",
+	"image/gif":     ".gif",
+	"image/webp":    ".webp",
+	"image/svg+xml": ".svg",
+}
+
+func attr(token html.Token, key string) string {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return strings.TrimSpace(a.Val)
+		}
+	}
+	return ""
+}
+
+func isVoid(tag string) bool {
+	switch tag {
+	case "area", "base", "br", "col", "embed", "hr", "img", "input", "link", "meta", "source", "track", "wbr":
+		return true
+	}
+	return false
+}
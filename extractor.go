@@ -0,0 +1,164 @@
+package goscraper
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Extractor extracts metadata from the tokens of a html document.
+// A new extractor is created for every parsed document, see Scraper.Extractors.
+// The page is reused once the document is parsed, it must not be kept past Finalize.
+type Extractor interface {
+	// Token is called for every token of the document until the parsing stops
+	Token(page *Page, token html.Token) error
+	// Finalize is called once the parsing is over, before the preview is returned
+	Finalize(page *Page) error
+}
+
+// Page is the state of the document being parsed, shared by its extractors
+type Page struct {
+	Doc *Document
+	// Url is the url of the document, Base the url relative references are resolved against
+	Url  *url.URL
+	Base *url.URL
+	// HeadPassed is set once the end of <head> is reached
+	HeadPassed bool
+
+	images  imageCandidates
+	ogImage bool
+}
+
+// Resolve resolves a possibly relative reference against the base url of the document
+func (page *Page) Resolve(ref string) (string, error) {
+	u, err := resolveUrl(page.Base, ref)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
+
+// Images returns the images found so far, by rank
+func (page *Page) Images() []Image {
+	return page.images.list()
+}
+
+type metaExtractor struct {
+	dcTitle       string
+	dcDescription string
+	themeColor    string
+}
+
+func (e *metaExtractor) Token(page *Page, token html.Token) error {
+	if token.Type == html.EndTagToken || token.Data != "meta" {
+		return nil
+	}
+	var property string
+	var content string
+	var media string
+	var hasContent bool
+	for _, attr := range token.Attr {
+		if attr.Key == "property" || attr.Key == "name" {
+			property = attr.Val
+		}
+		if attr.Key == "media" {
+			media = attr.Val
+		}
+		if attr.Key == "content" {
+			content = attr.Val
+			hasContent = true
+		}
+	}
+	property = cleanStr(property)
+	if len(property) > 0 && hasContent {
+		if page.Doc.Meta == nil {
+			page.Doc.Meta = map[string][]string{}
+		}
+		page.Doc.Meta[property] = append(page.Doc.Meta[property], content)
+	}
+	switch property {
+	case "dc.title", "dcterms.title":
+		if len(e.dcTitle) == 0 {
+			e.dcTitle = content
+		}
+	case "dc.description", "dcterms.description", "dcterms.abstract":
+		if len(e.dcDescription) == 0 {
+			e.dcDescription = content
+		}
+	case "theme-color":
+		// the color of the light scheme when there are several
+		if len(e.themeColor) == 0 && !containsFold(media, "dark") {
+			e.themeColor = strings.TrimSpace(content)
+		}
+	}
+	if len(token.Attr) != 2 {
+		return nil
+	}
+	preview := &page.Doc.Preview
+	switch ogProperty(page.Doc, property) {
+	case "og:site_name":
+		preview.Name = content
+	case "og:title":
+		preview.Title = content
+	case "og:description":
+		preview.Description = content
+	case "description":
+		if len(preview.Description) == 0 {
+			preview.Description = content
+		}
+	case "og:url":
+		preview.Link = content
+	case "copyright":
+		preview.Copyright = content
+	case "og:image":
+		page.ogImage = true
+		ogImgUrl, err := page.Resolve(content)
+		if err != nil {
+			return err
+		}
+		page.images.og = []Image{{Url: ogImgUrl}}
+	case "og:image:alt":
+		if len(page.images.og) > 0 {
+			page.images.og[0].Alt = content
+		}
+	}
+	return nil
+}
+
+func (e *metaExtractor) Finalize(page *Page) error {
+	preview := &page.Doc.Preview
+	if len(preview.Title) == 0 {
+		preview.Title = e.dcTitle
+	}
+	if len(preview.Description) == 0 {
+		preview.Description = e.dcDescription
+	}
+	preview.ThemeColor = e.themeColor
+	if tile := page.Doc.Meta["msapplication-tilecolor"]; len(preview.ThemeColor) == 0 && len(tile) > 0 {
+		preview.ThemeColor = strings.TrimSpace(tile[0])
+	}
+	return nil
+}
+
+type titleExtractor struct {
+	inTitle bool
+}
+
+func (e *titleExtractor) Token(page *Page, token html.Token) error {
+	switch token.Type {
+	case html.StartTagToken:
+		e.inTitle = token.Data == "title"
+	case html.EndTagToken:
+		e.inTitle = false
+	case html.TextToken:
+		if e.inTitle && len(page.Doc.Preview.Title) == 0 {
+			page.Doc.Preview.Title = token.Data
+		}
+	}
+	return nil
+}
+
+func (e *titleExtractor) Finalize(page *Page) error {
+	return nil
+}
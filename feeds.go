@@ -0,0 +1,59 @@
+package goscraper
+
+import (
+	"golang.org/x/net/html"
+)
+
+// Feed is a RSS or Atom feed advertised by a document
+type Feed struct {
+	Url   string `json:"url"`
+	Type  string `json:"type"`
+	Title string `json:"title,omitempty"`
+}
+
+type feedExtractor struct{}
+
+func (e *feedExtractor) Token(page *Page, token html.Token) error {
+	if token.Type == html.EndTagToken || token.Data != "link" {
+		return nil
+	}
+	var alternate, amp bool
+	var href, linkType, title string
+	for _, attr := range token.Attr {
+		switch attr.Key {
+		case "rel":
+			alternate = hasRel(attr.Val, "alternate")
+			amp = hasRel(attr.Val, "amphtml")
+		case "href":
+			href = attr.Val
+		case "type":
+			linkType = cleanStr(attr.Val)
+		case "title":
+			title = attr.Val
+		}
+	}
+	if len(href) == 0 {
+		return nil
+	}
+	hrefUrl, err := page.Resolve(href)
+	if err != nil {
+		return err
+	}
+	preview := &page.Doc.Preview
+	if alternate && (linkType == "application/rss+xml" || linkType == "application/atom+xml") {
+		for _, feed := range preview.Feeds {
+			if feed.Url == hrefUrl {
+				return nil
+			}
+		}
+		preview.Feeds = append(preview.Feeds, Feed{Url: hrefUrl, Type: linkType, Title: title})
+	}
+	if amp && len(preview.AMPURL) == 0 {
+		preview.AMPURL = hrefUrl
+	}
+	return nil
+}
+
+func (e *feedExtractor) Finalize(page *Page) error {
+	return nil
+}
@@ -1,16 +1,21 @@
 package goscraper
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
+	"path"
 	"regexp"
+	"runtime/debug"
 	"strings"
+	"time"
 
 	"golang.org/x/net/html"
-	"golang.org/x/net/html/charset"
 )
 
 var (
@@ -22,22 +27,254 @@ type Scraper struct {
 	Url                *url.URL
 	EscapedFragmentUrl *url.URL
 	MaxRedirect        int
+	// MaxDocumentLength limits the size of downloaded documents, 0 means no limit
+	MaxDocumentLength int64
+	// AllowErrorStatus parses non-2xx responses instead of returning an ErrHTTPStatus
+	AllowErrorStatus bool
+	// AnyContentType parses the documents that are neither html nor media, e.g. text/plain or
+	// xml, as html like the first versions did instead of returning an ErrUnsupportedContentType
+	AnyContentType bool
+	// Breaker, if set, fails fast on hosts with repeated errors
+	Breaker *CircuitBreaker
+	// RateLimiter, if set, throttles the requests to each host
+	RateLimiter *RateLimiter
+	// Header is added to every request
+	Header    http.Header
+	CookieJar http.CookieJar
+	// Username and Password are sent as basic auth when Username is not empty
+	Username string
+	Password string
+	// Retries is the number of times a GET is retried on transient errors (429, 502, 503,
+	// 504, connection errors), waiting Backoff (500ms by default) doubled on each attempt
+	// or what the Retry-After header asks for
+	Retries int
+	Backoff time.Duration
+	// Timeout bounds the whole scrape, the canonical and fragment refetches and the retries
+	// share what is left of it instead of starting a new timeout. Each request of the scrape,
+	// redirects included, gets a shrinking share of the time left, 1/n of it for the n-th, so
+	// that a slow one leaves time to the next. A request it interrupts fails with an ErrTimeout.
+	Timeout time.Duration
+	// UserAgent is sent with every request, GoScraper by default
+	UserAgent string
+	// RespectRobots returns ErrDisallowed instead of fetching pages forbidden to UserAgent by robots.txt,
+	// or marked noindex by a robots meta tag or a X-Robots-Tag header
+	RespectRobots bool
+	// SameHostRedirects and SameSchemeRedirects refuse http redirects to another host or scheme
+	// with an ErrRedirectRefused, and the canonical links to another host or scheme aren't
+	// followed either, see WarningCanonicalIgnored. The number of http redirects is limited to
+	// MaxRedirect, or to 9 when it's 0 as http.Client stops after 10 requests.
+	SameHostRedirects   bool
+	SameSchemeRedirects bool
+
+	// Streaming tokenizes html documents while they are downloaded and stops the download
+	// once the preview is complete, Body then only holds what was read
+	Streaming bool
+	// Complete, if set, replaces the default condition to stop parsing html documents once their
+	// head is parsed: a title, a description and an og:image. It's checked after each tag past the head.
+	Complete func(page *Page) bool
+	// ExpandOnly follows the redirects of the url without downloading the document, the
+	// document returned only holds the final url in Preview.Link and the Redirects
+	ExpandOnly bool
+	// HeadOnly stops parsing at the end of <head>, body images are ignored
+	HeadOnly bool
+	// PreferAMP builds the preview from the lighter AMP version of the document when there is one
+	PreferAMP bool
+	// BackgroundImages also collects the images of inline background-image styles
+	BackgroundImages bool
+	// InlineSVG uses an inline <svg>, preferably a logo, as a data: url image when the document has
+	// no other image. RasterizeSVG, if set, converts it to another format, e.g. png
+	InlineSVG    bool
+	RasterizeSVG func(svg []byte) (img []byte, mediaType string, err error)
+	// TableOfContents extracts the sections of documentation pages, the body is then parsed
+	// even when the preview is complete at the end of <head>
+	TableOfContents bool
+	// ContentFallback titles the documents without title with their first <h1>, and describes
+	// those without description with their first paragraphs, cut to FallbackLength characters (300 by default)
+	ContentFallback bool
+	FallbackLength  int
+	// CodeSamples extracts the first code block of the documents, the body is then parsed as well
+	CodeSamples bool
+	// MainContent extracts the main content of the documents into Document.Content, the body is
+	// then parsed as well. Its html is passed through Sanitizer, DefaultPolicy() by default.
+	MainContent bool
+	Sanitizer   Sanitizer
+	// ChunkSize, if set, cuts the text of the main content into Content.Chunks of at most
+	// ChunkSize characters, at its headings and paragraph boundaries
+	ChunkSize int
+	// Keywords, if set, is the number of keywords and entities extracted from the main content
+	Keywords int
+	// ProbeSite reads the well-known files of the site of the documents, security.txt, humans.txt
+	// and the app links files, into Document.Site, see Scraper.Site
+	ProbeSite bool
+	// Profile records the site facts of the documents scraped into the profile of their host,
+	// see SiteProfile
+	Profile bool
+	// Hints derives layout hints from the markup of the documents into Preview.Hints, the body
+	// is then parsed as well
+	Hints bool
+	// Extractors create the extractors run on each parsed html document after the built-in ones
+	Extractors []func() Extractor
+	// Archive, if set, stores the raw responses of the documents fetched, in Streaming mode
+	// only the part of the body that was read
+	Archive Archive
+	// RenderFetcher, if set, fetches again the documents that look like the empty shell of a
+	// single page application, e.g. a browser.Fetcher rendering their JavaScript
+	RenderFetcher Fetcher
+	// Classifier, if set, is asked how to scrape the url before it's fetched, to skip it
+	// or hand it to a special handler. The canonical and AMP refetches aren't classified.
+	Classifier func(u *url.URL) Classification
+	// DryRun validates the scraper and checks the cached robots.txt instead of fetching anything,
+	// the document returned only holds the Request that would have been sent
+	DryRun bool
+	// Blocklist, if set, refuses to fetch the documents of its hosts, redirects included
+	Blocklist *Blocklist
+	// Reputation, if set, checks the url of the documents before they're fetched and once their
+	// redirects are followed, the verdicts are reported in Document.Verdicts. BlockUnsafe fails
+	// the scrape of unsafe urls with ErrUnsafe instead.
+	Reputation  Reputation
+	BlockUnsafe bool
+	// Consent, if set, is asked whether the documents may be scraped before they're fetched,
+	// redirects included, the urls it denies fail with an ErrDenied. The redirects are only
+	// checked when the scraper follows them, a custom Fetcher follows its own without asking.
+	Consent Consent
+	// Metrics, if set, counts the scrapes, requests, downloaded bytes and failures
+	Metrics *Metrics
+	// MemoryBudget, if set, delays the fetches while the documents in flight hold too much memory
+	MemoryBudget *MemoryBudget
+	// AcceptEncodings are the content encodings the requests accept, gzip, deflate and br (brotli)
+	// by default. Responses in these encodings are decompressed, others are read as is.
+	AcceptEncodings []string
+	// Hooks, if set, are called on requests, responses, redirects and parsed documents
+	Hooks *Hooks
+	// Fetcher sends the requests of the documents, an http.Client honouring CookieJar and the
+	// redirect settings by default. See the browser package to render JavaScript before parsing.
+	Fetcher Fetcher
+	// Tenant, if set, scopes the caches of the scraper to the tenant, and its RateLimiter, Breaker,
+	// Blocklist, MemoryBudget and Metrics are used in place of the scraper's
+	Tenant *Tenant
+
+	redirectLimit int
+	// amp is set once the AMP version of the document is fetched
+	amp       bool
+	redirects []Redirect
+	// visited are the urls of the documents fetched during the scrape
+	visited []string
+	// reserved is the memory reserved from MemoryBudget, available what is left of it
+	reserved  int64
+	available int64
+	// records are the responses to archive once the scrape is over
+	records     []*ArchiveRecord
+	verdicts    []Verdict
+	connections []Connection
+	// requests counts the requests sent during the scrape, see budget
+	requests int32
 }
 
 type Document struct {
-	Body    bytes.Buffer
-	Preview DocumentPreview
+	Body       bytes.Buffer
+	Preview    DocumentPreview
+	StatusCode int
+	// Prefixes are the RDFa prefix mappings declared on <html> or <head>
+	Prefixes map[string]string
+	// Redirects are the http redirects followed during the scrape, canonical refetches included
+	Redirects []Redirect
+	// Chain are the urls of the documents fetched during the scrape, the scraped one followed by
+	// the canonical, fragment and AMP refetches
+	Chain []string
+	// Meta holds the content of every <meta> of the document by lowercased name or property
+	Meta map[string][]string
+	// Site are the facts about the site of the document, see Scraper.ProbeSite
+	Site *SiteInfo
+	// Content is the main content of the document, see Scraper.MainContent
+	Content *Content
+	// Extracted holds the values custom extractors store
+	Extracted map[string]interface{}
+	// Verdicts are the verdicts of Scraper.Reputation on the urls of the document, before and
+	// after its redirects
+	Verdicts []Verdict
+	// Connections are the connections the requests of the scrape were sent on, robots.txt
+	// and refetches included, to debug the origins answering differently
+	Connections []Connection
+	// Warnings are the problems that didn't fail the scrape but may have degraded the preview
+	Warnings []Warning
+	// Rendered is set when the document was an empty shell fetched again with RenderFetcher
+	Rendered bool
+	// Request is the request a DryRun would have sent
+	Request *http.Request
+	// BodyEncoding tells MarshalJSON whether and how to include Body
+	BodyEncoding BodyEncoding
+	// PreviewSchema tells MarshalJSON and PreviewJSON which schema to serialize Preview in
+	PreviewSchema PreviewSchema
+
+	stream io.Reader
+	closer io.Closer
+	// shell is set when the document looks like the empty shell of a single page application
+	shell bool
 }
 
 type DocumentPreview struct {
-	Icon        string
-	Name        string
-	Title       string
-	Description string
-	Images      []string
-	Link        string
+	Icon        string   `json:"icon"`
+	Name        string   `json:"name"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Images      []string `json:"images"`
+	// ImageDetails describes Images, in the same order
+	ImageDetails []Image `json:"image_details,omitempty"`
+	Link         string  `json:"link"`
+	MediaType    string  `json:"media_type,omitempty"`
+	Size         int64   `json:"size,omitempty"`
+	// PreconnectHosts are the hosts of <link rel="preconnect"> and <link rel="dns-prefetch">
+	PreconnectHosts []string `json:"preconnect_hosts,omitempty"`
+	// ThemeColor is the brand color of the site, from the theme-color meta tag
+	ThemeColor string `json:"theme_color,omitempty"`
+	// Logo is the schema.org logo of the site, Manifest the url of its web app manifest
+	Logo     string `json:"logo,omitempty"`
+	Manifest string `json:"manifest,omitempty"`
+	// License is the url of the rel="license" link, Copyright the copyright meta tag
+	License   string `json:"license,omitempty"`
+	Copyright string `json:"copyright,omitempty"`
+	// Profiles are the rel="me" and mailto: links and the schema.org sameAs profiles
+	Profiles []string `json:"profiles,omitempty"`
+	// Next and Prev are the rel="next" and rel="prev" links of paginated documents
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+	// Feeds are the RSS and Atom feeds of the document, AMPURL its AMP version
+	Feeds  []Feed `json:"feeds,omitempty"`
+	AMPURL string `json:"amp_url,omitempty"`
+	// SearchAction is the schema.org site search of the document, if any
+	SearchAction *SearchAction `json:"search_action,omitempty"`
+	// Videos are the og:video, twitter:player and <video> media of the document, Audios its og:audio
+	Videos []MediaMeta `json:"videos,omitempty"`
+	Audios []MediaMeta `json:"audios,omitempty"`
+	// TOC is the table of contents of documentation pages, see Scraper.TableOfContents
+	TOC []Section `json:"toc,omitempty"`
+	// CodeSample is the first code block of the document, see Scraper.CodeSamples
+	CodeSample *CodeSample `json:"code_sample,omitempty"`
+	// Hints are the layout hints of the document, see Scraper.Hints
+	Hints *PageHints `json:"hints,omitempty"`
+	// Citation is the bibliographic data of scholarly documents
+	Citation *Citation `json:"citation,omitempty"`
+	// Price is the price of product pages
+	Price *Price `json:"price,omitempty"`
+	// ExtractorVersion is the ExtractorVersion of the scraper that extracted the preview
+	ExtractorVersion int `json:"extractor_version,omitempty"`
 }
 
+// Scrape scrapes uri with the default options, it's kept for compatibility with the first
+// versions of the package and is the same as ScrapeContext on a Scraper with only Url and
+// MaxRedirect set. Set up a Scraper to use the other options or a context.
+//
+// Unlike the first versions:
+//   - the documents that are neither html nor media, e.g. text/plain or xml, fail with
+//     ErrUnsupportedContentType, see Scraper.AnyContentType,
+//   - the non-2xx responses fail with an ErrHTTPStatus, see Scraper.AllowErrorStatus,
+//   - the http redirects are limited to maxRedirect, or to 10 requests when it's 0 as before,
+//     past that the scrape fails with ErrTooManyRedirects,
+//   - the requests interrupted by a timeout fail with an ErrTimeout.
+//
+// Failed requests are not retried, as before: only Scraper.Retries enables retries.
+//
+// Deprecated: use ScrapeContext.
 func Scrape(uri string, maxRedirect int) (*Document, error) {
 	u, err := url.Parse(uri)
 	if err != nil {
@@ -46,18 +283,88 @@ func Scrape(uri string, maxRedirect int) (*Document, error) {
 	return (&Scraper{Url: u, MaxRedirect: maxRedirect}).Scrape()
 }
 
+// Scrape is ScrapeContext with a background context
 func (scraper *Scraper) Scrape() (*Document, error) {
-	doc, err := scraper.getDocument()
+	return scraper.ScrapeContext(context.Background())
+}
+
+// ScrapeContext is like Scrape, cancelling ctx aborts pending requests and retries.
+// A panic while scraping, e.g. in an extractor, is returned as an ErrPanic.
+func (scraper *Scraper) ScrapeContext(ctx context.Context) (doc *Document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			doc, err = nil, ErrPanic{Value: r, Stack: debug.Stack()}
+		}
+		// failed scrapes are archived too, to reproduce the failure
+		if archiveErr := scraper.storeArchive(); archiveErr != nil && err == nil {
+			doc, err = nil, archiveErr
+		}
+		scraper.Metrics.scrape(err)
+		if doc != nil {
+			scraper.Metrics.warn(doc.Warnings)
+		}
+	}()
+	scraper.useTenant()
+	scraper.startScrape()
+	defer scraper.releaseMemory()
+	if scraper.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, scraper.Timeout)
+		defer cancel()
+	}
+	if scraper.DryRun {
+		if err := scraper.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if doc, done, err := scraper.classified(ctx); done {
+		return doc, err
+	}
+	doc, err = scraper.getDocument(ctx)
 	if err != nil {
 		return nil, err
 	}
-	err = scraper.parseDocument(doc)
+	if scraper.DryRun || scraper.ExpandOnly {
+		return doc, nil
+	}
+	start := time.Now()
+	err = scraper.parseDocument(ctx, doc)
+	if err == nil && doc.shell && scraper.RenderFetcher != nil {
+		doc, err = scraper.render(ctx, scraper.Url)
+	}
+	if err == nil && scraper.ProbeSite {
+		doc.Site, err = scraper.Site(ctx)
+	}
+	if err == nil && scraper.Profile {
+		learnProfile(scraper.cacheKey(scraper.Url), scraper.Url, doc)
+	}
+	scraper.Hooks.parseComplete(doc, time.Since(start), err)
 	if err != nil {
 		return nil, err
 	}
 	return doc, nil
 }
 
+func (scraper *Scraper) startScrape() {
+	scraper.redirectLimit = scraper.MaxRedirect
+	scraper.redirects = nil
+	scraper.visited = nil
+	scraper.verdicts = nil
+	scraper.connections = nil
+	scraper.records = nil
+	scraper.requests = 0
+	scraper.amp = false
+}
+
+// startDocument points the scraper at another document of the scrape, the redirects,
+// verdicts and connections of the scrape are kept
+func (scraper *Scraper) startDocument(u *url.URL) {
+	scraper.Url = u
+	scraper.EscapedFragmentUrl = nil
+	scraper.visited = nil
+	scraper.amp = false
+}
+
 func (scraper *Scraper) getUrl() string {
 	if scraper.EscapedFragmentUrl != nil {
 		return scraper.EscapedFragmentUrl.String()
@@ -108,7 +415,7 @@ func (scraper *Scraper) toFragmentUrl() error {
 	return nil
 }
 
-func (scraper *Scraper) getDocument() (*Document, error) {
+func (scraper *Scraper) getDocument(ctx context.Context) (*Document, error) {
 	scraper.MaxRedirect -= 1
 	if strings.Contains(scraper.Url.String(), "#!") {
 		scraper.toFragmentUrl()
@@ -117,53 +424,279 @@ func (scraper *Scraper) getDocument() (*Document, error) {
 		scraper.EscapedFragmentUrl = scraper.Url
 	}
 
-	req, err := http.NewRequest("GET", scraper.getUrl(), nil)
+	req, err := scraper.newRequest(ctx, "GET", scraper.getUrl())
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Add("User-Agent", "GoScraper")
+	if err := scraper.checkBlocklist(req.URL); err != nil {
+		return nil, err
+	}
+	if err := scraper.checkConsent(ctx, req.URL); err != nil {
+		return nil, err
+	}
+	if scraper.DryRun {
+		return scraper.dryRun(req)
+	}
+	if err := scraper.checkReputation(ctx, req.URL); err != nil {
+		return nil, err
+	}
+	if scraper.RespectRobots {
+		if err := scraper.checkRobots(ctx, req.URL); err != nil {
+			return nil, err
+		}
+	}
 
-	resp, err := http.DefaultClient.Do(req)
-	if resp != nil {
-		defer resp.Body.Close()
+	if err := scraper.reserveMemory(ctx); err != nil {
+		return nil, err
 	}
+	resp, err := scraper.do(req)
 	if err != nil {
 		return nil, err
 	}
+	scraper.archiveResponse(resp)
+	if resp.Request.URL.String() != req.URL.String() {
+		if err := scraper.checkReputation(ctx, resp.Request.URL); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+	}
+	return scraper.readDocument(resp)
+}
+
+func (scraper *Scraper) readDocument(resp *http.Response) (*Document, error) {
+	var err error
+	// in streaming mode the body is closed once parsed
+	var streaming bool
+	defer func() {
+		if !streaming {
+			resp.Body.Close()
+		}
+	}()
+	if (resp.StatusCode < 200 || resp.StatusCode > 299) && !scraper.AllowErrorStatus {
+		return nil, ErrHTTPStatus{Code: resp.StatusCode}
+	}
+	if scraper.RespectRobots && robotsDisallowed(resp, productToken(scraper.userAgent())) {
+		return nil, fmt.Errorf("%w: %s by X-Robots-Tag", ErrDisallowed, resp.Request.URL)
+	}
 
+	scraper.redirects = append(scraper.redirects, redirectChain(resp)...)
 	if resp.Request.URL.String() != scraper.getUrl() {
 		scraper.EscapedFragmentUrl = nil
 		scraper.Url = resp.Request.URL
 	}
-	b, err := convertUTF8(resp.Body, resp.Header.Get("content-type"))
-	if err != nil {
-		return nil, err
+	// a refetch leading back to a document already fetched would ping-pong until MaxRedirect
+	if scraper.seen(scraper.getUrl()) {
+		return nil, fmt.Errorf("%w: %s", ErrCanonicalLoop, scraper.getUrl())
+	}
+	scraper.visited = append(scraper.visited, scraper.getUrl())
+	if scraper.ExpandOnly {
+		// drain a short body so that the connection can be reused
+		io.CopyN(io.Discard, resp.Body, 4<<10)
+		return &Document{
+			StatusCode:  resp.StatusCode,
+			Redirects:   append([]Redirect(nil), scraper.redirects...),
+			Chain:       append([]string(nil), scraper.visited...),
+			Verdicts:    append([]Verdict(nil), scraper.verdicts...),
+			Connections: append([]Connection(nil), scraper.connections...),
+			Preview:     DocumentPreview{Link: scraper.Url.String(), ExtractorVersion: ExtractorVersion},
+		}, nil
+	}
+	contentType := resp.Header.Get("content-type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !isHTML(mediaType) && !isMedia(mediaType) && !scraper.AnyContentType {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedContentType, mediaType)
+	}
+	var body io.Reader = resp.Body
+	if scraper.MaxDocumentLength > 0 {
+		if resp.ContentLength > scraper.MaxDocumentLength && !isMedia(mediaType) {
+			return nil, ErrTooLarge
+		}
+		body = &maxLengthReader{r: resp.Body, n: scraper.MaxDocumentLength}
+	}
+	if scraper.MemoryBudget != nil {
+		body = &budgetReader{r: body, scraper: scraper}
+	}
+	doc := &Document{
+		StatusCode:  resp.StatusCode,
+		Redirects:   append([]Redirect(nil), scraper.redirects...),
+		Chain:       append([]string(nil), scraper.visited...),
+		Verdicts:    append([]Verdict(nil), scraper.verdicts...),
+		Connections: append([]Connection(nil), scraper.connections...),
+		Preview:     DocumentPreview{Link: scraper.Url.String(), MediaType: mediaType, ExtractorVersion: ExtractorVersion},
+	}
+	switch {
+	case mediaType == "application/pdf":
+		// pdf metadata can be anywhere in the file, the whole body is needed up to a limit
+		if scraper.MaxDocumentLength <= 0 {
+			body = io.LimitReader(body, maxPDFLength)
+		}
+		_, err = io.Copy(&doc.Body, body)
+		if err != nil {
+			return nil, err
+		}
+		if scraper.MaxDocumentLength <= 0 && doc.Body.Len() >= maxPDFLength {
+			doc.warn(WarningTruncated, doc.Preview.Link, "pdf cut after %d bytes", doc.Body.Len())
+		}
+	case isMedia(mediaType):
+		// the url itself is the preview, don't download the file
+		if resp.ContentLength > 0 {
+			doc.Preview.Size = resp.ContentLength
+		}
+	case scraper.Streaming:
+		var fallback string
+		doc.stream, fallback, err = utf8Reader(bufio.NewReaderSize(body, 1024), contentType)
+		if err != nil {
+			return nil, err
+		}
+		if len(fallback) > 0 {
+			doc.warn(WarningCharsetFallback, doc.Preview.Link, "%s", fallback)
+		}
+		doc.closer = resp.Body
+		streaming = true
+	default:
+		b, fallback, err := convertUTF8(body, contentType, resp.ContentLength)
+		if err == io.ErrUnexpectedEOF && b.Len() > 0 {
+			// the connection was cut before the end of the body, keep what was received
+			doc.warn(WarningTruncated, doc.Preview.Link, "body cut after %d bytes", b.Len())
+		} else if err != nil {
+			return nil, err
+		}
+		if len(fallback) > 0 {
+			doc.warn(WarningCharsetFallback, doc.Preview.Link, "%s", fallback)
+		}
+		doc.Body = b
 	}
-	doc := &Document{Body: b, Preview: DocumentPreview{Link: scraper.Url.String()}}
 
 	return doc, nil
 }
 
-func convertUTF8(content io.Reader, contentType string) (bytes.Buffer, error) {
-	buff := bytes.Buffer{}
-	content, err := charset.NewReader(content, contentType)
+func (scraper *Scraper) newRequest(ctx context.Context, method, uri string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, uri, nil)
 	if err != nil {
-		return buff, err
+		return nil, err
 	}
-	_, err = io.Copy(&buff, content)
+	for k, v := range scraper.Header {
+		req.Header[k] = append([]string(nil), v...)
+	}
+	req.Header.Set("User-Agent", scraper.userAgent())
+	if len(req.Header.Get("Accept-Encoding")) == 0 {
+		req.Header.Set("Accept-Encoding", scraper.acceptEncoding())
+	}
+	if len(scraper.Username) > 0 {
+		req.SetBasicAuth(scraper.Username, scraper.Password)
+	}
+	return req, nil
+}
+
+func (scraper *Scraper) userAgent() string {
+	if len(scraper.UserAgent) > 0 {
+		return scraper.UserAgent
+	}
+	if ua := scraper.Header.Get("User-Agent"); len(ua) > 0 {
+		return ua
+	}
+	return "GoScraper"
+}
+
+// Fetcher fetches documents, *http.Client satisfies it
+type Fetcher interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+func (scraper *Scraper) fetcher() Fetcher {
+	if scraper.Fetcher != nil {
+		return scraper.Fetcher
+	}
+	return scraper.client()
+}
+
+func (scraper *Scraper) client() *http.Client {
+	return &http.Client{
+		Transport:     &budgetTransport{scraper: scraper, base: http.DefaultTransport},
+		Jar:           scraper.CookieJar,
+		CheckRedirect: scraper.checkRedirect,
+	}
+}
+
+func convertUTF8(content io.Reader, contentType string, size int64) (buff bytes.Buffer, fallback string, err error) {
+	if size > 0 && size <= 8<<20 {
+		buff.Grow(int(size))
+	}
+	r := getSniffReader()
+	defer putSniffReader(r)
+	r.Reset(content)
+	content, fallback, err = utf8Reader(r, contentType)
 	if err != nil {
-		return buff, err
+		return buff, "", err
 	}
-	return buff, nil
+	_, err = io.Copy(&buff, content)
+	return buff, fallback, err
 }
 
-func (scraper *Scraper) parseDocument(doc *Document) error {
-	t := html.NewTokenizer(&doc.Body)
-	var ogImage bool
-	var headPassed bool
+func (scraper *Scraper) parseDocument(ctx context.Context, doc *Document) error {
+	var t *html.Tokenizer
+	if doc.stream != nil {
+		// tokenize while downloading, keeping what was read in Body
+		defer doc.closer.Close()
+		t = html.NewTokenizer(io.TeeReader(doc.stream, &doc.Body))
+		doc.stream = nil
+	} else {
+		// read from a copy so that Body still holds the document once parsed
+		t = html.NewTokenizer(bytes.NewReader(doc.Body.Bytes()))
+	}
 	var hasFragment bool
 	var hasCanonical bool
 	var canonicalUrl *url.URL
+	var hasBase bool
+	// relative urls are resolved against <base href> if found, else against the document url
+	page := getPage(doc, scraper)
+	defer putPage(page)
+	extractors := []Extractor{&metaExtractor{}, &titleExtractor{}, &paginationExtractor{}, &feedExtractor{}, &mediaExtractor{}, &citationExtractor{}, &priceExtractor{}}
+	if scraper.BackgroundImages {
+		extractors = append(extractors, &backgroundExtractor{})
+	}
+	if scraper.InlineSVG {
+		extractors = append(extractors, &svgExtractor{rasterize: scraper.RasterizeSVG})
+	}
+	if scraper.TableOfContents {
+		extractors = append(extractors, &tocExtractor{})
+	}
+	if scraper.CodeSamples {
+		extractors = append(extractors, &codeExtractor{})
+	}
+	if scraper.Hints {
+		extractors = append(extractors, &hintsExtractor{})
+	}
+	if scraper.MainContent {
+		sanitizer := scraper.Sanitizer
+		if sanitizer == nil {
+			sanitizer = DefaultPolicy()
+		}
+		extractors = append(extractors, &mainContentExtractor{sanitizer: sanitizer, chunkSize: scraper.ChunkSize, keywords: scraper.Keywords})
+	}
+	if scraper.ContentFallback {
+		length := scraper.FallbackLength
+		if length <= 0 {
+			length = defaultFallbackLength
+		}
+		extractors = append(extractors, &contentExtractor{length: length})
+	}
+	if scraper.RenderFetcher != nil {
+		extractors = append(extractors, &shellExtractor{})
+	}
+	for _, newExtractor := range scraper.Extractors {
+		extractors = append(extractors, newExtractor())
+	}
+	finalize := func() error {
+		for _, extractor := range extractors {
+			if err := extractor.Finalize(page); err != nil {
+				return err
+			}
+		}
+		doc.Preview.ImageDetails = page.images.list()
+		doc.Preview.Images = imageUrls(doc.Preview.ImageDetails)
+		return nil
+	}
 	doc.Preview.Images = []string{}
 	// saves previews' link in case that <link rel="canonical"> is found after <meta property="og:url">
 	link := doc.Preview.Link
@@ -171,50 +704,121 @@ func (scraper *Scraper) parseDocument(doc *Document) error {
 	doc.Preview.Name = scraper.Url.Host
 	// set default icon to web root if <link rel="icon" href="/favicon.ico"> not found
 	doc.Preview.Icon = fmt.Sprintf("%s://%s%s", scraper.Url.Scheme, scraper.Url.Host, "/favicon.ico")
+	if isMedia(doc.Preview.MediaType) {
+		return scraper.parseMedia(doc)
+	}
 	for {
 		tokenType := t.Next()
 		if tokenType == html.ErrorToken {
-			return nil
+			if err := t.Err(); err == io.ErrUnexpectedEOF && doc.Body.Len() > 0 {
+				// the connection was cut while streaming, keep what was received
+				doc.warn(WarningTruncated, doc.Preview.Link, "body cut after %d bytes", doc.Body.Len())
+			} else if err != io.EOF {
+				return err
+			}
+			return finalize()
+		}
+		token := t.Token()
+		for _, extractor := range extractors {
+			if err := extractor.Token(page, token); err != nil {
+				return err
+			}
 		}
 		if tokenType != html.SelfClosingTagToken && tokenType != html.StartTagToken && tokenType != html.EndTagToken {
 			continue
 		}
-		token := t.Token()
 
 		switch token.Data {
+		case "html":
+			parsePrefixes(doc, token)
 		case "head":
+			parsePrefixes(doc, token)
 			if tokenType == html.EndTagToken {
-				headPassed = true
+				page.HeadPassed = true
 			}
 		case "body":
-			headPassed = true
+			page.HeadPassed = true
+
+		case "base":
+			for _, attr := range token.Attr {
+				if attr.Key == "href" && !hasBase {
+					baseUrl, err := resolveUrl(scraper.Url, attr.Val)
+					if err != nil {
+						return err
+					}
+					page.Base = baseUrl
+					hasBase = true
+				}
+			}
 
 		case "link":
 			var canonical bool
 			var hasIcon bool
+			var hasItemprop bool
+			var preconnect bool
+			var license bool
+			var profile bool
+			var manifest bool
 			var href string
 			for _, attr := range token.Attr {
-				if cleanStr(attr.Key) == "rel" && cleanStr(attr.Val) == "canonical" {
+				if (attr.Key == "rel" && hasRel(attr.Val, "me")) || isItempropSameAs(attr) {
+					profile = true
+				}
+				if attr.Key == "rel" && hasRel(attr.Val, "license") {
+					license = true
+				}
+				if attr.Key == "rel" && hasRel(attr.Val, "manifest") {
+					manifest = true
+				}
+				if attr.Key == "rel" && hasRel(attr.Val, "preconnect", "dns-prefetch") {
+					preconnect = true
+				}
+				if attr.Key == "itemprop" && isItempropImage(attr.Val) {
+					hasItemprop = true
+				}
+				if attr.Key == "rel" && isKey(attr.Val, "canonical") {
 					canonical = true
 				}
-				if cleanStr(attr.Key) == "rel" && strings.Contains(cleanStr(attr.Val),  "icon") {
+				if attr.Key == "rel" && containsFold(attr.Val, "icon") {
 					hasIcon = true
 				}
-				if cleanStr(attr.Key) == "href" {
+				if attr.Key == "href" {
 					href = attr.Val
 				}
-				if len(href) > 0 && canonical && link != href {
+			}
+			if len(href) == 0 {
+				break
+			}
+			hrefUrl, err := resolveUrl(page.Base, href)
+			if err != nil {
+				return err
+			}
+			if canonical && link != hrefUrl.String() {
+				if !scraper.amp && (scraper.SameHostRedirects && hrefUrl.Host != scraper.Url.Host || scraper.SameSchemeRedirects && hrefUrl.Scheme != scraper.Url.Scheme) {
+					doc.warn(WarningCanonicalIgnored, hrefUrl.String(), "canonical on another host or scheme not followed")
+				} else {
 					hasCanonical = true
-					var err error
-					canonicalUrl, err = url.Parse(href)
-					if err != nil {
-						return err
-					}
-				}
-				if len(href) > 0 && hasIcon {
-					doc.Preview.Icon = href
+					canonicalUrl = hrefUrl
 				}
 			}
+			if hasIcon {
+				doc.Preview.Icon = hrefUrl.String()
+			}
+			if hasItemprop {
+				page.images.itemprop = append(page.images.itemprop, Image{Url: hrefUrl.String()})
+			}
+			if license && len(doc.Preview.License) == 0 {
+				doc.Preview.License = hrefUrl.String()
+			}
+			if manifest && len(doc.Preview.Manifest) == 0 {
+				doc.Preview.Manifest = hrefUrl.String()
+			}
+			if profile {
+				doc.Preview.Profiles = appendUnique(doc.Preview.Profiles, hrefUrl.String())
+			}
+			if preconnect && len(hrefUrl.Host) > 0 {
+				doc.Preview.PreconnectHosts = appendUnique(doc.Preview.PreconnectHosts, hrefUrl.Host)
+			}
 
 		case "meta":
 			if len(token.Attr) != 2 {
@@ -224,108 +828,303 @@ func (scraper *Scraper) parseDocument(doc *Document) error {
 				hasFragment = true
 			}
 			var property string
+			var itemprop string
 			var content string
 			for _, attr := range token.Attr {
-				if cleanStr(attr.Key) == "property" || cleanStr(attr.Key) == "name" {
+				if attr.Key == "property" || attr.Key == "name" {
 					property = attr.Val
 				}
-				if cleanStr(attr.Key) == "content" {
+				if attr.Key == "itemprop" {
+					itemprop = attr.Val
+				}
+				if attr.Key == "content" {
 					content = attr.Val
 				}
 			}
-			switch cleanStr(property) {
-			case "og:site_name":
-				doc.Preview.Name = content
-			case "og:title":
-				doc.Preview.Title = content
-			case "og:description":
-				doc.Preview.Description = content
-			case "description":
-				if len(doc.Preview.Description) == 0 {
-					doc.Preview.Description = content
-				}
-			case "og:url":
-				doc.Preview.Link = content
-			case "og:image":
-				ogImage = true
-				ogImgUrl, err := url.Parse(content)
+			if isItempropImage(itemprop) && len(content) > 0 {
+				imgUrl, err := resolveUrl(page.Base, content)
 				if err != nil {
 					return err
 				}
-				if !ogImgUrl.IsAbs() {
-					ogImgUrl, err = url.Parse(fmt.Sprintf("%s://%s%s", scraper.Url.Scheme, scraper.Url.Host, ogImgUrl.Path))
-					if err != nil {
-						return err
-					}
+				page.images.itemprop = append(page.images.itemprop, Image{Url: imgUrl.String()})
+			}
+			if isKey(itemprop, "sameas") && len(content) > 0 {
+				doc.Preview.Profiles = appendUnique(doc.Preview.Profiles, content)
+			}
+			if scraper.RespectRobots && (isKey(property, "robots") || strings.EqualFold(property, productToken(scraper.userAgent()))) {
+				if noindex(content, productToken(scraper.userAgent())) {
+					return fmt.Errorf("%w: %s by robots meta tag", ErrDisallowed, scraper.Url)
 				}
-
-				doc.Preview.Images = []string{ogImgUrl.String()}
-
 			}
 
-		case "title":
-			if tokenType == html.StartTagToken {
-				t.Next()
-				token = t.Token()
-				if len(doc.Preview.Title) == 0 {
-					doc.Preview.Title = token.Data
+		case "a":
+			var license bool
+			var profile bool
+			var href string
+			for _, attr := range token.Attr {
+				if (attr.Key == "rel" && hasRel(attr.Val, "me")) || isItempropSameAs(attr) {
+					profile = true
+				}
+				if attr.Key == "rel" && hasRel(attr.Val, "license") {
+					license = true
+				}
+				if attr.Key == "href" {
+					href = attr.Val
 				}
 			}
+			if license && len(href) > 0 && len(doc.Preview.License) == 0 {
+				licenseUrl, err := resolveUrl(page.Base, href)
+				if err != nil {
+					return err
+				}
+				doc.Preview.License = licenseUrl.String()
+			}
+			if strings.HasPrefix(cleanStr(href), "mailto:") {
+				doc.Preview.Profiles = appendUnique(doc.Preview.Profiles, strings.TrimSpace(href))
+			} else if profile && len(href) > 0 {
+				profileUrl, err := resolveUrl(page.Base, href)
+				if err != nil {
+					return err
+				}
+				doc.Preview.Profiles = appendUnique(doc.Preview.Profiles, profileUrl.String())
+			}
 
-		case "img":
+		case "script":
+			var ldJSON bool
 			for _, attr := range token.Attr {
-				if cleanStr(attr.Key) == "src" {
-					imgUrl, err := url.Parse(attr.Val)
-					if err != nil {
-						return err
+				if attr.Key == "type" && isKey(attr.Val, "application/ld+json") {
+					ldJSON = true
+				}
+			}
+			if ldJSON && tokenType == html.StartTagToken {
+				t.Next()
+				for _, node := range jsonLDNodes(t.Token().Data) {
+					for _, sameAs := range jsonLDStrings(node, "sameAs") {
+						doc.Preview.Profiles = appendUnique(doc.Preview.Profiles, sameAs)
+					}
+					if action := searchAction(node); action != nil && doc.Preview.SearchAction == nil {
+						doc.Preview.SearchAction = action
 					}
-					if !imgUrl.IsAbs() {
-						doc.Preview.Images = append(doc.Preview.Images, fmt.Sprintf("%s://%s%s", scraper.Url.Scheme, scraper.Url.Host, imgUrl.Path))
-					} else {
-						doc.Preview.Images = append(doc.Preview.Images, attr.Val)
+					if price := offerPrice(node); price != nil && doc.Preview.Price == nil {
+						doc.Preview.Price = price
 					}
+					if logo := jsonLDLogo(node); len(logo) > 0 && len(doc.Preview.Logo) == 0 {
+						logoUrl, err := resolveUrl(page.Base, logo)
+						if err != nil {
+							return err
+						}
+						doc.Preview.Logo = logoUrl.String()
+					}
+				}
+			}
 
+		case "img":
+			var hasItemprop bool
+			var alt string
+			var ariaLabel string
+			src := imageSrc(token.Attr)
+			for _, attr := range token.Attr {
+				if attr.Key == "itemprop" && isItempropImage(attr.Val) {
+					hasItemprop = true
+				}
+				if attr.Key == "alt" {
+					alt = strings.TrimSpace(attr.Val)
+				}
+				if attr.Key == "aria-label" {
+					ariaLabel = strings.TrimSpace(attr.Val)
 				}
 			}
+			if len(src) == 0 {
+				break
+			}
+			imgUrl, err := resolveUrl(page.Base, src)
+			if err != nil {
+				return err
+			}
+			if len(alt) == 0 {
+				alt = ariaLabel
+			}
+			img := Image{Url: imgUrl.String(), Alt: alt}
+			if hasItemprop {
+				page.images.itemprop = append(page.images.itemprop, img)
+			} else {
+				page.images.body = append(page.images.body, img)
+			}
 		}
 
-		if hasCanonical && headPassed && scraper.MaxRedirect > 0 {
-			if !canonicalUrl.IsAbs() {
-				absCanonical, err := url.Parse(fmt.Sprintf("%s://%s%s", scraper.Url.Scheme, scraper.Url.Host, canonicalUrl.Path))
-				if err != nil {
-					return err
-				}
-				canonicalUrl = absCanonical
+		if hasCanonical && page.HeadPassed && scraper.amp {
+			// the canonical of an AMP page is the page it was found on
+			doc.Preview.Link = canonicalUrl.String()
+			hasCanonical = false
+		}
+
+		if hasCanonical && page.HeadPassed && scraper.MaxRedirect > 0 {
+			if scraper.seen(canonicalUrl.String()) {
+				return fmt.Errorf("%w: %s", ErrCanonicalLoop, canonicalUrl)
 			}
 			scraper.Url = canonicalUrl
 			scraper.EscapedFragmentUrl = nil
-			fdoc, err := scraper.getDocument()
+			fdoc, err := scraper.getDocument(ctx)
 			if err != nil {
 				return err
 			}
+			// the warnings about the first document still hold
+			fdoc.Warnings = append(doc.Warnings, fdoc.Warnings...)
 			*doc = *fdoc
-			return scraper.parseDocument(doc)
+			return scraper.parseDocument(ctx, doc)
 		}
 
-		if hasFragment && headPassed && scraper.MaxRedirect > 0 {
+		if hasFragment && page.HeadPassed && scraper.MaxRedirect > 0 {
 			scraper.toFragmentUrl()
-			fdoc, err := scraper.getDocument()
+			fdoc, err := scraper.getDocument(ctx)
 			if err != nil {
 				return err
 			}
+			fdoc.Warnings = append(doc.Warnings, fdoc.Warnings...)
 			*doc = *fdoc
-			return scraper.parseDocument(doc)
+			return scraper.parseDocument(ctx, doc)
 		}
 
-		if len(doc.Preview.Title) > 0 && len(doc.Preview.Description) > 0 && ogImage && headPassed {
-			return nil
+		if scraper.PreferAMP && !scraper.amp && len(doc.Preview.AMPURL) > 0 && !scraper.seen(doc.Preview.AMPURL) && page.HeadPassed && scraper.MaxRedirect > 0 {
+			ampUrl, err := url.Parse(doc.Preview.AMPURL)
+			if err != nil {
+				return err
+			}
+			scraper.amp = true
+			scraper.Url = ampUrl
+			scraper.EscapedFragmentUrl = nil
+			fdoc, err := scraper.getDocument(ctx)
+			if err != nil {
+				return err
+			}
+			// AMP pages usually don't advertise the feeds of their original
+			feeds := doc.Preview.Feeds
+			fdoc.Warnings = append(doc.Warnings, fdoc.Warnings...)
+			*doc = *fdoc
+			doc.Preview.AMPURL = ampUrl.String()
+			doc.Preview.Feeds = feeds
+			return scraper.parseDocument(ctx, doc)
+		}
+
+		if (scraper.complete(page) && !scraper.needsBody() || scraper.HeadOnly) && page.HeadPassed {
+			return finalize()
 		}
 
 	}
+}
+
+func (scraper *Scraper) complete(page *Page) bool {
+	if scraper.Complete != nil {
+		return scraper.Complete(page)
+	}
+	preview := &page.Doc.Preview
+	return len(preview.Title) > 0 && len(preview.Description) > 0 && page.ogImage
+}
+
+func (scraper *Scraper) seen(u string) bool {
+	for _, v := range scraper.visited {
+		if v == u {
+			return true
+		}
+	}
+	return false
+}
+
+func (scraper *Scraper) needsBody() bool {
+	return scraper.TableOfContents || scraper.CodeSamples || scraper.Hints || scraper.MainContent
+}
 
+func (scraper *Scraper) parseMedia(doc *Document) error {
+	// use the file name as title, pdf metadata may override it
+	if name := path.Base(scraper.Url.Path); name != "/" && name != "." {
+		doc.Preview.Title = name
+	}
+	switch {
+	case strings.HasPrefix(doc.Preview.MediaType, "image/"):
+		doc.Preview.Images = []string{doc.Preview.Link}
+		doc.Preview.ImageDetails = []Image{{Url: doc.Preview.Link}}
+	case doc.Preview.MediaType == "application/pdf":
+		doc.Preview.Size = int64(doc.Body.Len())
+		if title := pdfInfo(doc.Body.Bytes(), "Title"); len(title) > 0 {
+			doc.Preview.Title = title
+		}
+		doc.Preview.Description = pdfInfo(doc.Body.Bytes(), "Subject")
+	}
 	return nil
 }
 
+func isHTML(mediaType string) bool {
+	// documents without content type are parsed as html
+	return mediaType == "" || mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
+
+func isMedia(mediaType string) bool {
+	return mediaType == "application/pdf" ||
+		strings.HasPrefix(mediaType, "image/") ||
+		strings.HasPrefix(mediaType, "video/") ||
+		strings.HasPrefix(mediaType, "audio/")
+}
+
+func resolveUrl(base *url.URL, ref string) (*url.URL, error) {
+	refUrl, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return nil, err
+	}
+	return base.ResolveReference(refUrl), nil
+}
+
+func hasRel(rel string, types ...string) bool {
+	for {
+		rel = strings.TrimLeft(rel, " \t\n\r\f")
+		if len(rel) == 0 {
+			return false
+		}
+		r := firstField(rel)
+		rel = rel[len(r):]
+		for _, t := range types {
+			if strings.EqualFold(r, t) {
+				return true
+			}
+		}
+	}
+}
+
+func firstField(s string) string {
+	s = strings.TrimLeft(s, " \t\n\r\f")
+	if i := strings.IndexAny(s, " \t\n\r\f"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// hasPrefixFold and containsFold are strings.HasPrefix and strings.Contains ignoring the
+// case of s, for a lowercase ascii prefix or substr. Unlike lowercasing s they don't allocate.
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+func containsFold(s, substr string) bool {
+	for i := 0; i+len(substr) <= len(s); i++ {
+		if strings.EqualFold(s[i:i+len(substr)], substr) {
+			return true
+		}
+	}
+	return false
+}
+
+func isItempropSameAs(attr html.Attribute) bool {
+	return attr.Key == "itemprop" && isKey(attr.Val, "sameas")
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, v := range list {
+		if v == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
 func avoidByte(b byte) bool {
 	i := int(b)
 	if i == 127 || (i >= 0 && i <= 31) {
@@ -347,10 +1146,10 @@ func metaFragment(token html.Token) bool {
 	var content string
 
 	for _, attr := range token.Attr {
-		if cleanStr(attr.Key) == "name" {
+		if attr.Key == "name" {
 			name = attr.Val
 		}
-		if cleanStr(attr.Key) == "content" {
+		if attr.Key == "content" {
 			content = attr.Val
 		}
 	}
@@ -363,3 +1162,22 @@ func metaFragment(token html.Token) bool {
 func cleanStr(str string) string {
 	return strings.ToLower(strings.TrimSpace(str))
 }
+
+// isKey reports whether str is key once cleaned as by cleanStr, for a lowercase ascii key.
+// Unlike comparing cleanStr(str) it doesn't allocate when str has uppercase letters.
+func isKey(str, key string) bool {
+	str = strings.TrimSpace(str)
+	if len(str) != len(key) {
+		return false
+	}
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		if c != key[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,252 @@
+package goscraper
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const testPage = `<html><head><title>Title</title><meta name="description" content="Description"></head><body></body></html>`
+
+func TestContentTypes(t *testing.T) {
+	tests := []struct {
+		contentType    string
+		anyContentType bool
+		wantErr        error
+	}{
+		{"text/html; charset=utf-8", false, nil},
+		{"application/xhtml+xml", false, nil},
+		{"", false, nil},
+		{"text/plain", false, ErrUnsupportedContentType},
+		{"application/xml", false, ErrUnsupportedContentType},
+		{"text/plain", true, nil},
+		{"application/xml", true, nil},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header()["Content-Type"] = []string{tt.contentType}
+			io.WriteString(w, testPage)
+		}))
+		u, _ := url.Parse(srv.URL)
+		doc, err := (&Scraper{Url: u, MaxRedirect: 5, AnyContentType: tt.anyContentType}).Scrape()
+		srv.Close()
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%q, AnyContentType %v: got error %v, want %v", tt.contentType, tt.anyContentType, err, tt.wantErr)
+			continue
+		}
+		if err == nil && doc.Preview.Title != "Title" {
+			t.Errorf("%q, AnyContentType %v: got title %q", tt.contentType, tt.anyContentType, doc.Preview.Title)
+		}
+	}
+}
+
+// parsePage extracts the document of an html page served from u
+func parsePage(t *testing.T, scraper *Scraper, u, page string) *Document {
+	t.Helper()
+	doc, err := scraper.Replay(&ArchiveRecord{
+		Url:        u,
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": {"text/html; charset=utf-8"}},
+		Body:       []byte(page),
+	})
+	if err != nil {
+		t.Fatalf("%s: %v", u, err)
+	}
+	return doc
+}
+
+// scrapeServer serves the pages of the Scrape tests, counting the requests
+func scrapeServer(requests *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/redirect/"):
+			n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/redirect/"))
+			if n > 0 {
+				http.Redirect(w, r, "/redirect/"+strconv.Itoa(n-1), http.StatusFound)
+				return
+			}
+			io.WriteString(w, testPage)
+		case r.URL.Path == "/canonical":
+			io.WriteString(w, `<html><head><link rel="canonical" href="/"><title>Copy</title></head></html>`)
+		case r.URL.Path == "/text":
+			w.Header().Set("Content-Type", "text/plain")
+			io.WriteString(w, "plain text")
+		case r.URL.Path == "/unavailable":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case r.URL.Path == "/":
+			io.WriteString(w, testPage)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+// scrapeFixtures serves pages whose Scrape output is pinned to that of the first versions
+func scrapeFixtures() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/redirect/"):
+			n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/redirect/"))
+			if n > 0 {
+				http.Redirect(w, r, "/redirect/"+strconv.Itoa(n-1), http.StatusFound)
+				return
+			}
+			http.Redirect(w, r, "/article", http.StatusMovedPermanently)
+		case r.URL.Path == "/article":
+			io.WriteString(w, `<html><head><title>Page title</title>
+<meta property="og:title" content="Article title">
+<meta property="og:site_name" content="Example">
+<meta name="description" content="Meta description">
+<meta property="og:description" content="Article description">
+<meta property="og:image" content="/images/og.png">
+</head><body><img src="/images/body.png"></body></html>`)
+		case r.URL.Path == "/plain":
+			io.WriteString(w, `<html><head><title>Plain title</title>
+<meta name="description" content="Plain description">
+</head><body><p>Text</p><img src="/images/a.png"><img src="http://images.example.com/b.png"></body></html>`)
+		case r.URL.Path == "/canonical":
+			io.WriteString(w, `<html><head><title>Copy</title><link rel="canonical" href="/plain"></head><body></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+// TestScrapeBaseline checks that Scrape previews the fixtures as the first versions did
+func TestScrapeBaseline(t *testing.T) {
+	srv := scrapeFixtures()
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "http://")
+	article := DocumentPreview{
+		Icon:        srv.URL + "/favicon.ico",
+		Name:        "Example",
+		Title:       "Article title",
+		Description: "Article description",
+		Images:      []string{srv.URL + "/images/og.png"},
+		Link:        srv.URL + "/article",
+	}
+	plain := DocumentPreview{
+		Icon:        srv.URL + "/favicon.ico",
+		Name:        host,
+		Title:       "Plain title",
+		Description: "Plain description",
+		Images:      []string{srv.URL + "/images/a.png", "http://images.example.com/b.png"},
+		Link:        srv.URL + "/plain",
+	}
+	canonical := DocumentPreview{
+		Icon:   srv.URL + "/favicon.ico",
+		Name:   host,
+		Title:  "Copy",
+		Images: []string{},
+		Link:   srv.URL + "/canonical",
+	}
+	tests := []struct {
+		path        string
+		maxRedirect int
+		want        *DocumentPreview
+	}{
+		{"/article", 5, &article},
+		{"/article", 0, &article},
+		{"/plain", 5, &plain},
+		{"/redirect/3", 5, &article},
+		{"/redirect/3", 0, &article},
+		// http.Client stops after 10 requests
+		{"/redirect/8", 0, &article},
+		{"/redirect/9", 0, nil},
+		// the canonical is fetched while maxRedirect, counting the first request, allows it
+		{"/canonical", 5, &plain},
+		{"/canonical", 1, &canonical},
+		{"/canonical", 0, &canonical},
+	}
+	for _, tt := range tests {
+		doc, err := Scrape(srv.URL+tt.path, tt.maxRedirect)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("%s %d: got no error", tt.path, tt.maxRedirect)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %d: %v", tt.path, tt.maxRedirect, err)
+			continue
+		}
+		// the fields of the first versions
+		got := DocumentPreview{
+			Icon:        doc.Preview.Icon,
+			Name:        doc.Preview.Name,
+			Title:       doc.Preview.Title,
+			Description: doc.Preview.Description,
+			Images:      doc.Preview.Images,
+			Link:        doc.Preview.Link,
+		}
+		if !reflect.DeepEqual(got, *tt.want) {
+			t.Errorf("%s %d: got %+v, want %+v", tt.path, tt.maxRedirect, got, *tt.want)
+		}
+	}
+}
+
+// TestScrapeChanges checks the behaviors of Scrape that changed since the first versions
+func TestScrapeChanges(t *testing.T) {
+	var requests atomic.Int32
+	srv := scrapeServer(&requests)
+	defer srv.Close()
+	tests := []struct {
+		path        string
+		maxRedirect int
+		wantErr     func(error) bool
+		requests    int32
+	}{
+		// http redirects are limited to MaxRedirect, to 10 requests when it's 0 as before
+		{"/redirect/9", 0, nil, 10},
+		{"/redirect/10", 0, func(err error) bool { return errors.Is(err, ErrTooManyRedirects) }, 10},
+		{"/redirect/3", 2, func(err error) bool { return errors.Is(err, ErrTooManyRedirects) }, 3},
+		{"/text", 5, func(err error) bool { return errors.Is(err, ErrUnsupportedContentType) }, 1},
+		{"/missing", 5, func(err error) bool { return errors.As(err, &ErrHTTPStatus{}) }, 1},
+		// without Retries, transient failures aren't retried
+		{"/unavailable", 5, func(err error) bool {
+			var status ErrHTTPStatus
+			return errors.As(err, &status) && status.Code == http.StatusServiceUnavailable
+		}, 1},
+	}
+	for _, tt := range tests {
+		requests.Store(0)
+		_, err := Scrape(srv.URL+tt.path, tt.maxRedirect)
+		if tt.wantErr == nil && err != nil || tt.wantErr != nil && !tt.wantErr(err) {
+			t.Errorf("%s %d: unexpected error %v", tt.path, tt.maxRedirect, err)
+		}
+		if n := requests.Load(); n != tt.requests {
+			t.Errorf("%s %d: got %d requests, want %d", tt.path, tt.maxRedirect, n, tt.requests)
+		}
+	}
+}
+
+func TestRefetchWarnings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			w.Header().Set("Content-Type", "text/html; charset=bogus")
+			io.WriteString(w, `<html><head><link rel="canonical" href="/"><title>Old</title></head></html>`)
+			return
+		}
+		io.WriteString(w, testPage)
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL + "/old")
+	doc, err := (&Scraper{Url: u, MaxRedirect: 5}).Scrape()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Preview.Title != "Title" {
+		t.Errorf("got title %q, want the canonical's", doc.Preview.Title)
+	}
+	if len(doc.Warnings) != 1 || doc.Warnings[0].Kind != WarningCharsetFallback || doc.Warnings[0].Url != srv.URL+"/old" {
+		t.Errorf("got warnings %v, want the charset fallback of /old", doc.Warnings)
+	}
+}
@@ -0,0 +1,117 @@
+package goscraper
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	// minHeroWidth is the width from which an image is large enough to lead a card
+	minHeroWidth = 600
+	// heroWords is the number of words of the body after which an image doesn't open the page anymore
+	heroWords = 50
+	// minFormText is the number of words of content outside forms from which a page isn't
+	// dominated by its forms
+	minFormText = 150
+)
+
+var videoEmbedRegexp = regexp.MustCompile(`(?i)^(https?:)?//([a-z0-9-]+\.)*(youtube\.com|youtube-nocookie\.com|youtu\.be|vimeo\.com|dailymotion\.com|twitch\.tv|wistia\.(com|net)|loom\.com|streamable\.com|jwplayer\.com|brightcove\.net)/`)
+
+// PageHints are layout hints derived from the markup of a document, for clients to choose
+// the layout of its card, see Scraper.Hints
+type PageHints struct {
+	// HeroImage is set when a large image leads the document: a large og:image, a
+	// summary_large_image twitter card or a large image opening the body
+	HeroImage bool `json:"hero_image"`
+	// VideoEmbed is set when the document has a video, an og:video or a player iframe
+	VideoEmbed bool `json:"video_embed"`
+	// FormDominated is set when the document is mostly a form, e.g. a login or signup page
+	FormDominated bool `json:"form_dominated"`
+	// Words is the approximate number of words of the content, navigation and forms left out
+	Words int `json:"words"`
+}
+
+type hintsExtractor struct {
+	hints    PageHints
+	skipped  int
+	controls int
+}
+
+func (e *hintsExtractor) Token(page *Page, token html.Token) error {
+	switch token.Type {
+	case html.StartTagToken, html.SelfClosingTagToken:
+		if !page.HeadPassed {
+			return nil
+		}
+		if skippedElements[token.Data] && token.Type == html.StartTagToken {
+			e.skipped++
+		}
+		switch token.Data {
+		case "input":
+			switch strings.ToLower(attrValue(token, "type")) {
+			case "hidden", "submit", "button", "reset", "image":
+			default:
+				e.controls++
+			}
+		case "select", "textarea":
+			e.controls++
+		case "img":
+			if e.hints.Words < heroWords && e.skipped == 0 {
+				if w, err := strconv.Atoi(strings.TrimSuffix(attrValue(token, "width"), "px")); err == nil && w >= minHeroWidth {
+					e.hints.HeroImage = true
+				}
+			}
+		case "video":
+			e.hints.VideoEmbed = true
+		case "iframe":
+			if videoEmbedRegexp.MatchString(attrValue(token, "src")) {
+				e.hints.VideoEmbed = true
+			}
+		}
+	case html.EndTagToken:
+		if skippedElements[token.Data] && e.skipped > 0 {
+			e.skipped--
+		}
+	case html.TextToken:
+		if page.HeadPassed && e.skipped == 0 {
+			e.hints.Words += len(strings.Fields(token.Data))
+		}
+	}
+	return nil
+}
+
+func (e *hintsExtractor) Finalize(page *Page) error {
+	doc := page.Doc
+	if len(doc.Preview.Videos) > 0 {
+		e.hints.VideoEmbed = true
+	}
+	if w, err := strconv.Atoi(firstMeta(doc, "og:image:width")); err == nil && w >= minHeroWidth {
+		e.hints.HeroImage = true
+	}
+	if firstMeta(doc, "twitter:card") == "summary_large_image" && (len(page.images.og) > 0 || len(firstMeta(doc, "twitter:image")) > 0) {
+		e.hints.HeroImage = true
+	}
+	e.hints.FormDominated = e.controls >= 2 && e.hints.Words < minFormText
+	hints := e.hints
+	doc.Preview.Hints = &hints
+	return nil
+}
+
+func attrValue(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+	return ""
+}
+
+func firstMeta(doc *Document, name string) string {
+	if values := doc.Meta[name]; len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package goscraper
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Hooks are called as a scraper works, e.g. to record metrics, traces or logs.
+// Any of them may be nil, they must be safe for concurrent use when the Hooks are shared.
+type Hooks struct {
+	// OnRequest is called before each attempt of a request
+	OnRequest func(req *http.Request)
+	// OnResponse is called once the body of a response is closed, or when the request failed,
+	// with the number of body bytes read and the time since the request was sent
+	OnResponse func(req *http.Request, status int, bytes int64, duration time.Duration, err error)
+	// OnRedirect is called for each http redirect followed
+	OnRedirect func(from, to *url.URL, status int)
+	// OnParseComplete is called when the parsing of a document ends, refetches included
+	OnParseComplete func(doc *Document, duration time.Duration, err error)
+}
+
+func (hooks *Hooks) request(req *http.Request) {
+	if hooks != nil && hooks.OnRequest != nil {
+		hooks.OnRequest(req)
+	}
+}
+
+func (hooks *Hooks) response(req *http.Request, resp *http.Response, err error, start time.Time) {
+	if hooks == nil || hooks.OnResponse == nil {
+		return
+	}
+	if err != nil {
+		hooks.OnResponse(req, 0, 0, time.Since(start), err)
+		return
+	}
+	resp.Body = &hookedBody{ReadCloser: resp.Body, done: func(n int64) {
+		hooks.OnResponse(req, resp.StatusCode, n, time.Since(start), nil)
+	}}
+}
+
+func (hooks *Hooks) redirect(from, to *url.URL, status int) {
+	if hooks != nil && hooks.OnRedirect != nil {
+		hooks.OnRedirect(from, to, status)
+	}
+}
+
+func (hooks *Hooks) parseComplete(doc *Document, duration time.Duration, err error) {
+	if hooks != nil && hooks.OnParseComplete != nil {
+		hooks.OnParseComplete(doc, duration, err)
+	}
+}
+
+type hookedBody struct {
+	io.ReadCloser
+	n    int64
+	done func(n int64)
+}
+
+func (b *hookedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *hookedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.done != nil {
+		b.done(b.n)
+		b.done = nil
+	}
+	return err
+}
@@ -0,0 +1,98 @@
+package goscraper
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Image is a preview image with its accessible description
+type Image struct {
+	Url string `json:"url"`
+	// Alt is the alt text or aria-label of the image, or its og:image:alt
+	Alt string `json:"alt,omitempty"`
+}
+
+// imageCandidates keeps the images found in a document by source, og:image first,
+// then itemprop="image" and itemprop="logo" values, hero section backgrounds, the body
+// images and finally the other backgrounds
+type imageCandidates struct {
+	og         []Image
+	itemprop   []Image
+	hero       []Image
+	body       []Image
+	background []Image
+}
+
+// list returns the candidates by rank without duplicates, a duplicate can provide
+// the description missing from the first occurrence
+func (c *imageCandidates) list() []Image {
+	images := []Image{}
+	seen := map[string]int{}
+	for _, source := range [][]Image{c.og, c.itemprop, c.hero, c.body, c.background} {
+		for _, img := range source {
+			i, ok := seen[img.Url]
+			if !ok {
+				seen[img.Url] = len(images)
+				images = append(images, img)
+			} else if len(images[i].Alt) == 0 {
+				images[i].Alt = img.Alt
+			}
+		}
+	}
+	return images
+}
+
+func imageUrls(images []Image) []string {
+	urls := make([]string, len(images))
+	for i, img := range images {
+		urls[i] = img.Url
+	}
+	return urls
+}
+
+func isItempropImage(itemprop string) bool {
+	switch cleanStr(itemprop) {
+	case "image", "logo":
+		return true
+	}
+	return false
+}
+
+var lazyAttributes = []string{"data-src", "data-lazy-src", "data-original"}
+
+func imageSrc(attrs []html.Attribute) string {
+	var src, srcset, dataSrcset string
+	lazy := len(lazyAttributes)
+	var lazySrc string
+	for _, attr := range attrs {
+		switch attr.Key {
+		case "src":
+			src = attr.Val
+		case "srcset":
+			srcset = attr.Val
+		case "data-srcset":
+			dataSrcset = attr.Val
+		default:
+			for i := 0; i < lazy; i++ {
+				if attr.Key == lazyAttributes[i] && len(strings.TrimSpace(attr.Val)) > 0 {
+					lazy, lazySrc = i, attr.Val
+				}
+			}
+		}
+	}
+	if len(lazySrc) > 0 {
+		return strings.TrimSpace(lazySrc)
+	}
+	src = strings.TrimSpace(src)
+	if len(src) > 0 && !hasPrefixFold(src, "data:") {
+		return src
+	}
+	// src is missing or a placeholder, use the first candidate of the srcset
+	for _, set := range []string{dataSrcset, srcset} {
+		if field := firstField(set); len(field) > 0 {
+			return strings.TrimSuffix(field, ",")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,141 @@
+package goscraper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// BodyEncoding selects how Document.MarshalJSON serializes the body of the document
+type BodyEncoding int
+
+const (
+	// OmitBody leaves the body out, the default
+	OmitBody BodyEncoding = iota
+	// BodyUTF8 includes the body as a string
+	BodyUTF8
+	// BodyBase64 includes the body encoded in base64
+	BodyBase64
+)
+
+type documentJSON struct {
+	StatusCode  int                    `json:"status_code,omitempty"`
+	Preview     interface{}            `json:"preview"`
+	Prefixes    map[string]string      `json:"prefixes,omitempty"`
+	Redirects   []Redirect             `json:"redirects,omitempty"`
+	Chain       []string               `json:"chain,omitempty"`
+	Verdicts    []Verdict              `json:"verdicts,omitempty"`
+	Warnings    []Warning              `json:"warnings,omitempty"`
+	Connections []Connection           `json:"connections,omitempty"`
+	Rendered    bool                   `json:"rendered,omitempty"`
+	Meta        map[string][]string    `json:"meta,omitempty"`
+	Site        *SiteInfo              `json:"site,omitempty"`
+	Content     *Content               `json:"content,omitempty"`
+	Extracted   map[string]interface{} `json:"extracted,omitempty"`
+	Body        string                 `json:"body,omitempty"`
+	// BodyEncoding tells how to read Body, "utf-8" or "base64"
+	BodyEncoding string `json:"body_encoding,omitempty"`
+}
+
+// MarshalJSON serializes the document without its body, unless BodyEncoding asks for it,
+// and its preview in the schema of PreviewSchema. It has a value receiver so that documents
+// are serialized the same whether they are passed by value or by pointer.
+func (doc Document) MarshalJSON() ([]byte, error) {
+	d := documentJSON{
+		StatusCode:  doc.StatusCode,
+		Preview:     doc.Preview,
+		Prefixes:    doc.Prefixes,
+		Redirects:   doc.Redirects,
+		Chain:       doc.Chain,
+		Verdicts:    doc.Verdicts,
+		Warnings:    doc.Warnings,
+		Connections: doc.Connections,
+		Rendered:    doc.Rendered,
+		Meta:        doc.Meta,
+		Site:        doc.Site,
+		Content:     doc.Content,
+		Extracted:   doc.Extracted,
+	}
+	if doc.PreviewSchema == SchemaV2 {
+		d.Preview = doc.Preview.V2()
+	}
+	switch doc.BodyEncoding {
+	case BodyUTF8:
+		d.Body = doc.Body.String()
+		d.BodyEncoding = "utf-8"
+	case BodyBase64:
+		d.Body = base64.StdEncoding.EncodeToString(doc.Body.Bytes())
+		d.BodyEncoding = "base64"
+	}
+	return json.Marshal(d)
+}
+
+// PreviewJSON serializes the preview of the document only, in the schema of PreviewSchema.
+// It can't be named Preview, the name of the field holding the preview.
+func (doc *Document) PreviewJSON() ([]byte, error) {
+	if doc.PreviewSchema == SchemaV2 {
+		return json.Marshal(doc.Preview.V2())
+	}
+	return json.Marshal(doc.Preview)
+}
+
+// UnmarshalJSON reads a document serialized by MarshalJSON, in either preview schema.
+// Body is set when the document was serialized with it, and BodyEncoding and PreviewSchema
+// are set so that the document is serialized back the same.
+func (doc *Document) UnmarshalJSON(data []byte) error {
+	var d struct {
+		documentJSON
+		Preview json.RawMessage `json:"preview"`
+	}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	*doc = Document{
+		StatusCode:  d.StatusCode,
+		Prefixes:    d.Prefixes,
+		Redirects:   d.Redirects,
+		Chain:       d.Chain,
+		Verdicts:    d.Verdicts,
+		Warnings:    d.Warnings,
+		Connections: d.Connections,
+		Rendered:    d.Rendered,
+		Meta:        d.Meta,
+		Site:        d.Site,
+		Content:     d.Content,
+		Extracted:   d.Extracted,
+	}
+	if len(d.Preview) > 0 {
+		var version struct {
+			Version int `json:"version"`
+		}
+		if err := json.Unmarshal(d.Preview, &version); err != nil {
+			return err
+		}
+		if version.Version == 2 {
+			var preview PreviewV2
+			if err := json.Unmarshal(d.Preview, &preview); err != nil {
+				return err
+			}
+			doc.Preview = preview.V1()
+			doc.PreviewSchema = SchemaV2
+		} else if err := json.Unmarshal(d.Preview, &doc.Preview); err != nil {
+			return err
+		}
+	}
+	switch d.BodyEncoding {
+	case "":
+	case "utf-8":
+		doc.Body.WriteString(d.Body)
+		doc.BodyEncoding = BodyUTF8
+	case "base64":
+		body, err := base64.StdEncoding.DecodeString(d.Body)
+		if err != nil {
+			return err
+		}
+		doc.Body.Write(body)
+		doc.BodyEncoding = BodyBase64
+	default:
+		return fmt.Errorf("goscraper: unknown body encoding %q", d.BodyEncoding)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package goscraper
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDocumentMarshalJSON(t *testing.T) {
+	tests := []struct {
+		encoding BodyEncoding
+		want     string
+		notWant  string
+	}{
+		{OmitBody, `"title":"Title"`, `"body"`},
+		{BodyUTF8, `"body":"\u003cp\u003eété\u003c/p\u003e","body_encoding":"utf-8"`, ""},
+		{BodyBase64, `"body":"PHA+w6l0w6k8L3A+","body_encoding":"base64"`, ""},
+	}
+	for _, tt := range tests {
+		doc := Document{StatusCode: 200, Preview: DocumentPreview{Title: "Title"}, BodyEncoding: tt.encoding}
+		doc.Body.WriteString("<p>été</p>")
+		// a document is serialized the same by value and by pointer
+		byValue, err := json.Marshal(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byPointer, err := json.Marshal(&doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(byValue) != string(byPointer) {
+			t.Errorf("encoding %d: by value %s, by pointer %s", tt.encoding, byValue, byPointer)
+		}
+		if !strings.Contains(string(byValue), tt.want) {
+			t.Errorf("encoding %d: got %s, want it to contain %s", tt.encoding, byValue, tt.want)
+		}
+		if len(tt.notWant) > 0 && strings.Contains(string(byValue), tt.notWant) {
+			t.Errorf("encoding %d: got %s, want no %s", tt.encoding, byValue, tt.notWant)
+		}
+	}
+}
+
+func TestCompressDocument(t *testing.T) {
+	for _, tt := range []struct {
+		encoding BodyEncoding
+		schema   PreviewSchema
+	}{
+		{OmitBody, SchemaV1},
+		{BodyUTF8, SchemaV1},
+		{BodyBase64, SchemaV2},
+	} {
+		doc := &Document{
+			StatusCode: 200,
+			Preview: DocumentPreview{
+				Title:        "Title",
+				Images:       []string{"https://example.com/a.png"},
+				ImageDetails: []Image{{Url: "https://example.com/a.png", Alt: "A"}},
+				Link:         "https://example.com/",
+				Price:        &Price{Amount: "10.00", Currency: "EUR"},
+			},
+			Redirects:     []Redirect{{Url: "http://example.com/", StatusCode: 301}},
+			Chain:         []string{"https://example.com/"},
+			Warnings:      []Warning{{Kind: WarningTruncated, Url: "https://example.com/", Message: "body cut"}},
+			Connections:   []Connection{{Host: "example.com:443", Reused: true, IdleTime: time.Second}},
+			Meta:          map[string][]string{"description": {"Description"}},
+			Site:          &SiteInfo{Host: "https://example.com", Fetched: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)},
+			Content:       &Content{Text: "Text"},
+			BodyEncoding:  tt.encoding,
+			PreviewSchema: tt.schema,
+		}
+		doc.Body.WriteString("<p>été</p>")
+		b, err := CompressDocument(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := DecompressDocument(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.encoding == OmitBody {
+			doc.Body.Reset()
+		}
+		if !bytes.Equal(got.Body.Bytes(), doc.Body.Bytes()) {
+			t.Errorf("encoding %d: got body %q, want %q", tt.encoding, got.Body.String(), doc.Body.String())
+		}
+		got.Body, doc.Body = bytes.Buffer{}, bytes.Buffer{}
+		if !reflect.DeepEqual(got, doc) {
+			t.Errorf("encoding %d, schema %d: got %+v, want %+v", tt.encoding, tt.schema, got, doc)
+		}
+	}
+
+	// uncompressed JSON is read as well
+	if doc, err := DecompressDocument([]byte(`{"status_code":200,"preview":{"title":"Title"}}`)); err != nil || doc.Preview.Title != "Title" {
+		t.Errorf("got %+v, %v", doc, err)
+	}
+}
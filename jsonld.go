@@ -0,0 +1,90 @@
+package goscraper
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// jsonLDNodes returns the objects of a JSON-LD script in document order,
+// each object before the ones nested in it
+func jsonLDNodes(text string) []map[string]interface{} {
+	dec := json.NewDecoder(strings.NewReader(text))
+	var nodes []map[string]interface{}
+	if _, err := decodeJSONLD(dec, &nodes); err != nil {
+		return nil
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil
+	}
+	return nodes
+}
+
+// decodeJSONLD decodes the next value of dec token by token, as unmarshaling
+// into a map would lose the order of the keys
+func decodeJSONLD(dec *json.Decoder, nodes *[]map[string]interface{}) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		node := map[string]interface{}{}
+		*nodes = append(*nodes, node)
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSONLD(dec, nodes)
+			if err != nil {
+				return nil, err
+			}
+			node[key.(string)] = value
+		}
+		_, err = dec.Token()
+		return node, err
+	case json.Delim('['):
+		list := []interface{}{}
+		for dec.More() {
+			value, err := decodeJSONLD(dec, nodes)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err = dec.Token()
+		return list, err
+	}
+	return token, nil
+}
+
+func jsonLDLogo(node map[string]interface{}) string {
+	switch logo := node["logo"].(type) {
+	case string:
+		return logo
+	case map[string]interface{}:
+		if u, ok := logo["url"].(string); ok {
+			return u
+		}
+		if u, ok := logo["contentUrl"].(string); ok {
+			return u
+		}
+	}
+	return ""
+}
+
+func jsonLDStrings(node map[string]interface{}, key string) []string {
+	var values []string
+	switch v := node[key].(type) {
+	case string:
+		values = append(values, v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+	return values
+}
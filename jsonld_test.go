@@ -0,0 +1,54 @@
+package goscraper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSONLDOrder(t *testing.T) {
+	const page = `<html><head><script type="application/ld+json">{
+		"@type": "WebSite",
+		"potentialAction": {"@type": "SearchAction", "target": "https://example.com/search?q={q}", "query-input": "required name=q"},
+		"subjectOf": {
+			"@type": "WebSite",
+			"potentialAction": {"@type": "SearchAction", "target": "https://example.com/other?s={s}", "query-input": "required name=s"}
+		}
+	}</script></head></html>`
+	doc := parsePage(t, &Scraper{}, "https://example.com/", page)
+	want := &SearchAction{Target: "https://example.com/search?q={q}", QueryInput: "q"}
+	if !reflect.DeepEqual(doc.Preview.SearchAction, want) {
+		t.Errorf("got search action %+v, want %+v", doc.Preview.SearchAction, want)
+	}
+}
+
+func TestJSONLDOffers(t *testing.T) {
+	const page = `<html><head><script type="application/ld+json">{
+		"@type": "Product",
+		"offers": {"@type": "Offer", "price": "10.00", "priceCurrency": "eur"},
+		"isSimilarTo": {"@type": "Product", "offers": {"@type": "Offer", "price": 20, "priceCurrency": "USD"}},
+		"isRelatedTo": {"@type": "Product", "offers": {"@type": "Offer", "price": 30, "priceCurrency": "GBP"}}
+	}</script></head></html>`
+	doc := parsePage(t, &Scraper{}, "https://example.com/", page)
+	want := &Price{Amount: "10.00", Currency: "EUR", Raw: "10.00"}
+	if !reflect.DeepEqual(doc.Preview.Price, want) {
+		t.Errorf("got price %+v, want %+v", doc.Preview.Price, want)
+	}
+}
+
+func TestJSONLDOrganization(t *testing.T) {
+	const page = `<html><head><script type="application/ld+json">{
+		"@type": "Organization",
+		"logo": {"@type": "ImageObject", "url": "/logo.png"},
+		"sameAs": ["https://twitter.com/example", "https://github.com/example"],
+		"founder": {"@type": "Person", "sameAs": "https://twitter.com/founder", "image": {"@type": "ImageObject"}},
+		"parentOrganization": {"@type": "Organization", "logo": "/parent.png", "sameAs": "https://github.com/parent"}
+	}</script></head></html>`
+	doc := parsePage(t, &Scraper{}, "https://example.com/", page)
+	if want := "https://example.com/logo.png"; doc.Preview.Logo != want {
+		t.Errorf("got logo %q, want %q", doc.Preview.Logo, want)
+	}
+	want := []string{"https://twitter.com/example", "https://github.com/example", "https://twitter.com/founder", "https://github.com/parent"}
+	if !reflect.DeepEqual(doc.Preview.Profiles, want) {
+		t.Errorf("got profiles %v, want %v", doc.Preview.Profiles, want)
+	}
+}
@@ -0,0 +1,177 @@
+package goscraper
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Term is a keyword or an entity of the main content, see Scraper.Keywords
+type Term struct {
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+	// Score is the frequency of the term in the words of the content
+	Score float64 `json:"score"`
+}
+
+var stopwords = map[string]map[string]bool{
+	"en": wordSet("the of and to a in is it that for on was with as are be this by at or from have an not but they his her she he we you your i so if all can has had its were been their there which will would what when who more about one also than them into only other some out up no do does"),
+	"fr": wordSet("le la les de des du un une et est en que qui dans pour pas sur au aux ce ces il elle ils elles nous vous je tu on ne se sa son ses leur leurs par plus avec mais ou été être avoir a sont cette comme tout fait"),
+	"de": wordSet("der die das und ist in zu den von mit sich des auf für nicht ein eine einer eines dem im es an als auch er sie wir ihr ich sind war wird bei oder aus nach wie aber nur noch so um am hat haben werden"),
+	"es": wordSet("el la los las de del y en que un una es por con no se su sus para al lo como más pero sus le ya o este esta son fue ha muy también entre sin sobre ser hay todo"),
+	"it": wordSet("il lo la i gli le di del della dei e è in che un una per non con si da al alla sono come più ma anche questo questa ha nel nella o se tra sul"),
+	"pt": wordSet("o a os as de do da dos das e em que um uma é para com não se por no na mais como mas ao foi ele ela seu sua são também já ou entre"),
+	"nl": wordSet("de het een en van is in op te dat die voor met zijn niet aan er als ook maar bij om door naar dan of wordt nog worden heeft hij zij wij ik je"),
+}
+
+func wordSet(words string) map[string]bool {
+	set := map[string]bool{}
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+func contentLanguage(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if _, ok := stopwords[tag]; ok {
+		return tag
+	}
+	return ""
+}
+
+func detectLanguage(words []string) string {
+	best, bestCount := "", 0
+	for lang, set := range stopwords {
+		count := 0
+		for _, w := range words {
+			if set[w] {
+				count++
+			}
+		}
+		if count > bestCount || count == bestCount && count > 0 && lang < best {
+			best, bestCount = lang, count
+		}
+	}
+	return best
+}
+
+type contentWord struct {
+	text     string
+	lower    string
+	capital  bool
+	sentence bool
+}
+
+func contentWords(text string) []contentWord {
+	var words []contentWord
+	sentence := true
+	start := -1
+	for i, r := range text + " " {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || start >= 0 && (r == '\'' || r == '’' || r == '-') {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			w := strings.TrimRight(text[start:i], "'’-")
+			first, _ := utf8.DecodeRuneInString(w)
+			words = append(words, contentWord{text: w, lower: strings.ToLower(w), capital: unicode.IsUpper(first), sentence: sentence})
+			sentence = false
+			start = -1
+		}
+		switch r {
+		case '.', '!', '?', '\n', ':', '"', '“', '«':
+			sentence = true
+		}
+	}
+	return words
+}
+
+func keywords(words []contentWord, lang string, n int) (terms, entities []Term) {
+	stop := stopwords[lang]
+	counts := map[string]int{}
+	for _, w := range words {
+		if utf8.RuneCountInString(w.lower) < 3 || stop[w.lower] || isNumber(w.lower) {
+			continue
+		}
+		counts[stemPossessive(w.lower)]++
+	}
+	// merge the plurals into their singular when both are used
+	for term, count := range counts {
+		if singular := strings.TrimSuffix(term, "s"); singular != term && counts[singular] > 0 {
+			counts[singular] += count
+			delete(counts, term)
+		}
+	}
+
+	// capitalized words mid-sentence, a capitalized word starting a sentence is an
+	// entity only if it's seen capitalized elsewhere
+	capitalized := map[string]bool{}
+	for _, w := range words {
+		if w.capital && !w.sentence {
+			capitalized[w.text] = true
+		}
+	}
+	// german capitalizes all its nouns, only their runs are entities
+	minRun := 1
+	if lang == "de" {
+		minRun = 2
+	}
+	entityCounts := map[string]int{}
+	var run []string
+	flush := func() {
+		if len(run) >= minRun && len(run) <= 4 {
+			entityCounts[strings.Join(run, " ")]++
+		}
+		run = run[:0]
+	}
+	for i, w := range words {
+		entity := w.capital && !stop[w.lower] && (!w.sentence || capitalized[w.text])
+		if !entity || len(run) > 0 && w.sentence {
+			flush()
+		}
+		if entity {
+			run = append(run, stemPossessive(w.text))
+		}
+		if i == len(words)-1 {
+			flush()
+		}
+	}
+	return topTerms(counts, len(words), n), topTerms(entityCounts, len(words), n)
+}
+
+func topTerms(counts map[string]int, total, n int) []Term {
+	var terms []Term
+	for text, count := range counts {
+		terms = append(terms, Term{Text: text, Count: count, Score: float64(count) / float64(total)})
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].Count != terms[j].Count {
+			return terms[i].Count > terms[j].Count
+		}
+		return terms[i].Text < terms[j].Text
+	})
+	if len(terms) > n {
+		terms = terms[:n]
+	}
+	return terms
+}
+
+func stemPossessive(w string) string {
+	return strings.TrimSuffix(strings.TrimSuffix(w, "'s"), "’s")
+}
+
+func isNumber(w string) bool {
+	for _, r := range w {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package goscraper
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContentLanguage(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"en", "en"},
+		{"en-US", "en"},
+		{"FR_fr", "fr"},
+		{" de ", "de"},
+		{"ja", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := contentLanguage(tt.tag); got != tt.want {
+			t.Errorf("contentLanguage(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"the cat is on the mat", "en"},
+		{"le chat est sur la table", "fr"},
+		{"der Hund ist in dem Garten", "de"},
+		{"xyz abc", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := detectLanguage(strings.Fields(tt.text)); got != tt.want {
+			t.Errorf("detectLanguage(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestContentWords(t *testing.T) {
+	got := contentWords(`Hello, world's “Best” day-to-day- 42.`)
+	want := []contentWord{
+		{text: "Hello", lower: "hello", capital: true, sentence: true},
+		{text: "world's", lower: "world's"},
+		{text: "Best", lower: "best", capital: true, sentence: true},
+		{text: "day-to-day", lower: "day-to-day"},
+		{text: "42", lower: "42"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("contentWords = %+v, want %+v", got, want)
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		lang     string
+		n        int
+		terms    []Term
+		entities []Term
+	}{
+		{
+			name:     "stopwords, numbers and short words left out",
+			text:     "The Go team released Go 1.22. The release improves the team tools. Tools matter.",
+			lang:     "en",
+			n:        2,
+			terms:    []Term{{Text: "team", Count: 2, Score: 2.0 / 15}, {Text: "tools", Count: 2, Score: 2.0 / 15}},
+			entities: []Term{{Text: "Go", Count: 2, Score: 2.0 / 15}},
+		},
+		{
+			name:  "plurals merged",
+			text:  "cat cats cats dog",
+			lang:  "en",
+			n:     5,
+			terms: []Term{{Text: "cat", Count: 3, Score: 3.0 / 4}, {Text: "dog", Count: 1, Score: 1.0 / 4}},
+		},
+		{
+			name:  "possessives",
+			text:  "Apple's phone. Apple sells.",
+			lang:  "en",
+			n:     1,
+			terms: []Term{{Text: "apple", Count: 2, Score: 2.0 / 4}},
+		},
+		{
+			name:     "german runs",
+			text:     "Heute spricht Angela Merkel im Bundestag.",
+			lang:     "de",
+			n:        5,
+			terms:    []Term{{Text: "angela", Count: 1, Score: 1.0 / 6}, {Text: "bundestag", Count: 1, Score: 1.0 / 6}, {Text: "heute", Count: 1, Score: 1.0 / 6}, {Text: "merkel", Count: 1, Score: 1.0 / 6}, {Text: "spricht", Count: 1, Score: 1.0 / 6}},
+			entities: []Term{{Text: "Angela Merkel", Count: 1, Score: 1.0 / 6}},
+		},
+	}
+	for _, tt := range tests {
+		terms, entities := keywords(contentWords(tt.text), tt.lang, tt.n)
+		if !reflect.DeepEqual(terms, tt.terms) {
+			t.Errorf("%s: terms %+v, want %+v", tt.name, terms, tt.terms)
+		}
+		if !reflect.DeepEqual(entities, tt.entities) {
+			t.Errorf("%s: entities %+v, want %+v", tt.name, entities, tt.entities)
+		}
+	}
+}
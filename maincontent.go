@@ -0,0 +1,273 @@
+package goscraper
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const maxContent = 1 << 20
+
+// Content is the main content of a document, see Scraper.MainContent
+type Content struct {
+	// HTML is the markup of the main content, passed through Scraper.Sanitizer
+	HTML string `json:"html"`
+	// Reader is the main content simplified for reader views: headings, paragraphs, lists, quotes,
+	// code, tables, links and images, their urls resolved. It's passed through Scraper.Sanitizer as well.
+	Reader string `json:"reader"`
+	// Text is the text of the main content, its blocks separated by blank lines
+	Text string `json:"text"`
+	// Chunks are the sections of Text cut at its headings and paragraph boundaries to at
+	// most Scraper.ChunkSize characters, when it's set
+	Chunks []Chunk `json:"chunks,omitempty"`
+	// Language is the language of the main content, declared by the document or else detected
+	// among those whose stopwords are known: en, fr, de, es, it, pt and nl
+	Language string `json:"language,omitempty"`
+	// Keywords are the most frequent terms of the main content, stopwords left out, and Entities
+	// its most frequent names, see Scraper.Keywords
+	Keywords []Term `json:"keywords,omitempty"`
+	Entities []Term `json:"entities,omitempty"`
+	// Fingerprint is the SimHash of the text, to detect near duplicates across documents
+	Fingerprint Fingerprint `json:"fingerprint,omitempty"`
+	// Truncated is set when the main content was longer than what is read of it
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+var blockElements = map[string]bool{
+	"p": true, "div": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"li": true, "ul": true, "ol": true, "dl": true, "dt": true, "dd": true, "blockquote": true,
+	"pre": true, "figure": true, "figcaption": true, "table": true, "tr": true, "section": true,
+	"article": true, "main": true, "header": true, "hr": true, "br": true,
+}
+
+// mainContentExtractor reads the first element marked as the main content of the document:
+// itemprop="articleBody", <article>, <main> or role="main", or else its whole body without
+// navigation, forms and scripts
+type mainContentExtractor struct {
+	sanitizer Sanitizer
+	chunkSize int
+	keywords  int
+	// lang is the lang attribute of <html>
+	lang string
+
+	marked, body contentCapture
+	// container is the tag of the marked element being read, nested the count of open
+	// elements of the same tag in it
+	container string
+	nested    int
+	done      bool
+}
+
+type contentCapture struct {
+	tokens    []html.Token
+	size      int
+	skipped   int
+	truncated bool
+}
+
+func (c *contentCapture) add(token html.Token, inMarked bool) {
+	switch token.Type {
+	case html.StartTagToken:
+		if skippedElements[token.Data] && !(inMarked && token.Data == "header") {
+			c.skipped++
+			return
+		}
+	case html.EndTagToken:
+		if skippedElements[token.Data] && !(inMarked && token.Data == "header") {
+			if c.skipped > 0 {
+				c.skipped--
+			}
+			return
+		}
+	case html.CommentToken, html.DoctypeToken:
+		return
+	}
+	if c.skipped > 0 || c.truncated {
+		return
+	}
+	c.size += len(token.Data)
+	for _, attr := range token.Attr {
+		c.size += len(attr.Key) + len(attr.Val)
+	}
+	if c.size > maxContent {
+		c.truncated = true
+		return
+	}
+	c.tokens = append(c.tokens, token)
+}
+
+func (e *mainContentExtractor) Token(page *Page, token html.Token) error {
+	if token.Type == html.StartTagToken && token.Data == "html" {
+		e.lang = attrValue(token, "lang")
+	}
+	if !page.HeadPassed || e.done {
+		return nil
+	}
+	if token.Type == html.StartTagToken && len(e.container) == 0 && len(e.marked.tokens) == 0 && isMainContent(token) {
+		e.container = token.Data
+		e.nested = 0
+		return nil
+	}
+	if len(e.container) > 0 {
+		switch {
+		case token.Type == html.StartTagToken && token.Data == e.container:
+			e.nested++
+		case token.Type == html.EndTagToken && token.Data == e.container:
+			if e.nested == 0 {
+				e.container = ""
+				// the marked content is enough, the rest of the body isn't needed anymore,
+				// unless it's empty and another one may follow
+				e.done = len(textOf(e.marked.tokens)) > 0
+				if !e.done {
+					e.marked = contentCapture{}
+				}
+				return nil
+			}
+			e.nested--
+		}
+		e.marked.add(token, true)
+		return nil
+	}
+	if token.Data != "body" && token.Data != "html" {
+		e.body.add(token, false)
+	}
+	return nil
+}
+
+func (e *mainContentExtractor) Finalize(page *Page) error {
+	capture := &e.marked
+	if len(textOf(capture.tokens)) == 0 {
+		capture = &e.body
+	}
+	var raw strings.Builder
+	for _, token := range capture.tokens {
+		raw.WriteString(token.String())
+	}
+	blocks := textBlocks(capture.tokens)
+	content := &Content{
+		HTML:      e.sanitizer.Sanitize(raw.String()),
+		Reader:    e.sanitizer.Sanitize(readerHTML(page, capture.tokens)),
+		Truncated: capture.truncated,
+	}
+	content.Text, content.Chunks = chunks(blocks, e.chunkSize)
+	words := contentWords(content.Text)
+	content.Language = e.language(page.Doc, words)
+	content.Fingerprint = simhash(words)
+	if e.keywords > 0 {
+		content.Keywords, content.Entities = keywords(words, content.Language, e.keywords)
+	}
+	if content.Truncated {
+		page.Doc.warn(WarningTruncated, page.Url.String(), "main content cut at %d bytes", maxContent)
+	}
+	page.Doc.Content = content
+	return nil
+}
+
+func (e *mainContentExtractor) language(doc *Document, words []contentWord) string {
+	for _, tag := range []string{e.lang, firstMeta(doc, "og:locale"), firstMeta(doc, "language"), firstMeta(doc, "dc.language")} {
+		if lang := contentLanguage(tag); len(lang) > 0 {
+			return lang
+		}
+	}
+	lower := make([]string, len(words))
+	for i, w := range words {
+		lower[i] = w.lower
+	}
+	return detectLanguage(lower)
+}
+
+func isMainContent(token html.Token) bool {
+	if token.Data == "article" || token.Data == "main" {
+		return true
+	}
+	for _, attr := range token.Attr {
+		switch {
+		case attr.Key == "itemprop" && isKey(attr.Val, "articleBody"):
+			return true
+		case attr.Key == "role" && isKey(attr.Val, "main"):
+			return true
+		}
+	}
+	return false
+}
+
+type textBlock struct {
+	text string
+	// heading is the level of the heading the block is, 0 for other blocks
+	heading int
+}
+
+func textOf(tokens []html.Token) string {
+	blocks := textBlocks(tokens)
+	texts := make([]string, len(blocks))
+	for i, block := range blocks {
+		texts[i] = block.text
+	}
+	return strings.Join(texts, "\n\n")
+}
+
+func textBlocks(tokens []html.Token) []textBlock {
+	var blocks []textBlock
+	var block []byte
+	pre := 0
+	heading := 0
+	flush := func() {
+		if text := strings.TrimSpace(string(block)); len(text) > 0 {
+			blocks = append(blocks, textBlock{text: text, heading: heading})
+		}
+		block = block[:0]
+	}
+	for _, token := range tokens {
+		switch token.Type {
+		case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+			if token.Data == "pre" {
+				if token.Type == html.StartTagToken {
+					pre++
+				} else if token.Type == html.EndTagToken && pre > 0 {
+					pre--
+				}
+			}
+			if blockElements[token.Data] {
+				flush()
+			}
+			if level := headingLevel(token.Data); level > 0 {
+				heading = 0
+				if token.Type == html.StartTagToken {
+					heading = level
+				}
+			}
+		case html.TextToken:
+			if pre > 0 {
+				block = append(block, token.Data...)
+				continue
+			}
+			text := strings.Join(strings.Fields(token.Data), " ")
+			if len(text) == 0 {
+				if len(token.Data) > 0 && len(block) > 0 && block[len(block)-1] != ' ' {
+					block = append(block, ' ')
+				}
+				continue
+			}
+			if isSpace(token.Data[0]) && len(block) > 0 && block[len(block)-1] != ' ' {
+				block = append(block, ' ')
+			}
+			block = append(block, text...)
+			if isSpace(token.Data[len(token.Data)-1]) {
+				block = append(block, ' ')
+			}
+		}
+	}
+	flush()
+	return blocks
+}
+
+func headingLevel(tag string) int {
+	if len(tag) == 2 && tag[0] == 'h' && tag[1] >= '1' && tag[1] <= '6' {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,215 @@
+package goscraper
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// MediaMeta is a video or audio of a document
+type MediaMeta struct {
+	Url string `json:"url"`
+	// Type is the mime type of the media, text/html for an embeddable player
+	Type   string `json:"type,omitempty"`
+	Width  int    `json:"width,omitempty"`
+	Height int    `json:"height,omitempty"`
+}
+
+// mediaExtractor reads the og:video, og:audio and twitter:player tags, the structured
+// properties (e.g. og:video:width) apply to the last og:video or og:audio tag.
+// The <video> elements of the body come after, their posters are preview images.
+type mediaExtractor struct {
+	videos []MediaMeta
+	audios []MediaMeta
+	player *MediaMeta
+	stream *MediaMeta
+
+	html5 []MediaMeta
+	// video is the <video> being read, its <source> share its dimensions
+	video *MediaMeta
+}
+
+func (e *mediaExtractor) Token(page *Page, token html.Token) error {
+	// a text reading "video" isn't a tag
+	switch token.Type {
+	case html.StartTagToken, html.SelfClosingTagToken, html.EndTagToken:
+	default:
+		return nil
+	}
+	switch token.Data {
+	case "video", "source":
+		return e.videoToken(page, token)
+	}
+	if token.Type == html.EndTagToken || token.Data != "meta" {
+		return nil
+	}
+	var property, content string
+	for _, attr := range token.Attr {
+		switch attr.Key {
+		case "property", "name":
+			property = cleanStr(attr.Val)
+		case "content":
+			content = strings.TrimSpace(attr.Val)
+		}
+	}
+	if len(content) == 0 {
+		return nil
+	}
+
+	var media *[]MediaMeta
+	switch {
+	case strings.HasPrefix(property, "og:video"):
+		media = &e.videos
+		property = strings.TrimPrefix(property, "og:video")
+	case strings.HasPrefix(property, "og:audio"):
+		media = &e.audios
+		property = strings.TrimPrefix(property, "og:audio")
+	case property == "twitter:player":
+		u, err := page.Resolve(content)
+		if err != nil {
+			return err
+		}
+		e.player = &MediaMeta{Url: u, Type: "text/html"}
+		return nil
+	case property == "twitter:player:stream":
+		u, err := page.Resolve(content)
+		if err != nil {
+			return err
+		}
+		e.stream = &MediaMeta{Url: u}
+		return nil
+	case property == "twitter:player:stream:content_type":
+		if e.stream != nil {
+			e.stream.Type = content
+		}
+		return nil
+	case property == "twitter:player:width" && e.player != nil:
+		e.player.Width, _ = strconv.Atoi(content)
+		return nil
+	case property == "twitter:player:height" && e.player != nil:
+		e.player.Height, _ = strconv.Atoi(content)
+		return nil
+	default:
+		return nil
+	}
+
+	switch property {
+	case "", ":url", ":secure_url":
+		u, err := page.Resolve(content)
+		if err != nil {
+			return err
+		}
+		// og:video:url is the same as og:video, og:video:secure_url is the https url of the last one
+		n := len(*media)
+		switch {
+		case n > 0 && (*media)[n-1].Url == u:
+		case property == ":secure_url" && n > 0:
+			(*media)[n-1].Url = u
+		default:
+			*media = append(*media, MediaMeta{Url: u})
+		}
+	case ":type", ":width", ":height":
+		n := len(*media)
+		if n == 0 {
+			*media = append(*media, MediaMeta{})
+			n++
+		}
+		m := &(*media)[n-1]
+		switch property {
+		case ":type":
+			m.Type = content
+		case ":width":
+			m.Width, _ = strconv.Atoi(content)
+		case ":height":
+			m.Height, _ = strconv.Atoi(content)
+		}
+	}
+	return nil
+}
+
+func (e *mediaExtractor) videoToken(page *Page, token html.Token) error {
+	if token.Type == html.EndTagToken {
+		if token.Data == "video" {
+			e.video = nil
+		}
+		return nil
+	}
+	if token.Data == "source" && e.video == nil {
+		// <source> of an <audio> or <picture>
+		return nil
+	}
+	var src, poster, mediaType, alt string
+	var width, height int
+	for _, attr := range token.Attr {
+		switch attr.Key {
+		case "src":
+			src = strings.TrimSpace(attr.Val)
+		case "poster":
+			poster = strings.TrimSpace(attr.Val)
+		case "type":
+			mediaType = attr.Val
+		case "width":
+			width, _ = strconv.Atoi(attr.Val)
+		case "height":
+			height, _ = strconv.Atoi(attr.Val)
+		case "aria-label", "title":
+			if len(alt) == 0 {
+				alt = attr.Val
+			}
+		}
+	}
+	if token.Data == "video" {
+		if len(poster) > 0 {
+			posterUrl, err := page.Resolve(poster)
+			if err != nil {
+				return err
+			}
+			page.images.body = append(page.images.body, Image{Url: posterUrl, Alt: alt})
+		}
+		e.video = &MediaMeta{Width: width, Height: height}
+		if token.Type == html.SelfClosingTagToken {
+			e.video = nil
+		}
+	}
+	if len(src) == 0 || strings.HasPrefix(src, "blob:") {
+		return nil
+	}
+	u, err := page.Resolve(src)
+	if err != nil {
+		return err
+	}
+	video := MediaMeta{Url: u, Type: mediaType, Width: width, Height: height}
+	if token.Data == "source" {
+		video.Width, video.Height = e.video.Width, e.video.Height
+	}
+	e.html5 = append(e.html5, video)
+	return nil
+}
+
+func (e *mediaExtractor) Finalize(page *Page) error {
+	videos := e.videos
+	if e.player != nil {
+		videos = append(videos, *e.player)
+	}
+	if e.stream != nil {
+		videos = append(videos, *e.stream)
+	}
+	videos = append(videos, e.html5...)
+	page.Doc.Preview.Videos = uniqueMedia(videos)
+	page.Doc.Preview.Audios = uniqueMedia(e.audios)
+	return nil
+}
+
+func uniqueMedia(media []MediaMeta) []MediaMeta {
+	var out []MediaMeta
+	seen := map[string]bool{}
+	for _, m := range media {
+		if len(m.Url) == 0 || seen[m.Url] {
+			continue
+		}
+		seen[m.Url] = true
+		out = append(out, m)
+	}
+	return out
+}
@@ -0,0 +1,37 @@
+package goscraper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMediaExtractor(t *testing.T) {
+	tests := []struct {
+		name       string
+		page       string
+		wantVideos []MediaMeta
+		wantAudios []MediaMeta
+	}{
+		{"og video", `<meta property="og:video" content="/v.mp4"><meta property="og:video:type" content="video/mp4"><meta property="og:video:width" content="640">`,
+			[]MediaMeta{{Url: "http://example.com/v.mp4", Type: "video/mp4", Width: 640}}, nil},
+		{"secure url", `<meta property="og:video" content="http://example.com/v.mp4"><meta property="og:video:secure_url" content="https://example.com/v.mp4">`,
+			[]MediaMeta{{Url: "https://example.com/v.mp4"}}, nil},
+		{"og audio", `<meta property="og:audio" content="/a.mp3">`,
+			nil, []MediaMeta{{Url: "http://example.com/a.mp3"}}},
+		{"twitter player", `<meta name="twitter:player" content="/embed"><meta name="twitter:player:width" content="480">`,
+			[]MediaMeta{{Url: "http://example.com/embed", Type: "text/html", Width: 480}}, nil},
+		{"video sources", `<body><video width="320" height="240"><source src="/a.webm" type="video/webm"><source src="/a.mp4"></video></body>`,
+			[]MediaMeta{{Url: "http://example.com/a.webm", Type: "video/webm", Width: 320, Height: 240}, {Url: "http://example.com/a.mp4", Width: 320, Height: 240}}, nil},
+		{"audio source", `<body><audio><source src="/a.mp3"></audio></body>`, nil, nil},
+		{"text reading video", `<body><p>video</p><source src="/s.mp4"><p>source</p></body>`, nil, nil},
+	}
+	for _, tt := range tests {
+		doc := parsePage(t, &Scraper{}, "http://example.com/page", tt.page)
+		if !reflect.DeepEqual(doc.Preview.Videos, tt.wantVideos) {
+			t.Errorf("%s: got videos %+v, want %+v", tt.name, doc.Preview.Videos, tt.wantVideos)
+		}
+		if !reflect.DeepEqual(doc.Preview.Audios, tt.wantAudios) {
+			t.Errorf("%s: got audios %+v, want %+v", tt.name, doc.Preview.Audios, tt.wantAudios)
+		}
+	}
+}
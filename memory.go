@@ -0,0 +1,115 @@
+package goscraper
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+const defaultReservation = 512 << 10
+
+// MemoryBudget bounds the memory held by the documents being scraped. A single budget is meant
+// to be shared by all the scrapers of a batch: a fetch waits while the budget is spent, bytes
+// read past what a fetch reserved are counted without waiting.
+type MemoryBudget struct {
+	// Limit is the number of bytes the documents in flight may hold
+	Limit int64
+
+	mu   sync.Mutex
+	used int64
+	// released is closed and replaced when memory is released
+	released chan struct{}
+}
+
+func NewMemoryBudget(limit int64) *MemoryBudget {
+	return &MemoryBudget{Limit: limit}
+}
+
+// InUse returns the number of bytes currently reserved
+func (b *MemoryBudget) InUse() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.used
+}
+
+// acquire reserves n bytes, waiting until they are available or ctx is done.
+// A reservation larger than the limit is granted once nothing else is reserved.
+func (b *MemoryBudget) acquire(ctx context.Context, n int64) error {
+	for {
+		b.mu.Lock()
+		if b.used == 0 || b.used+n <= b.Limit {
+			b.used += n
+			b.mu.Unlock()
+			return nil
+		}
+		if b.released == nil {
+			b.released = make(chan struct{})
+		}
+		released := b.released
+		b.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
+	}
+}
+
+func (b *MemoryBudget) grow(n int64) {
+	b.mu.Lock()
+	b.used += n
+	b.mu.Unlock()
+}
+
+func (b *MemoryBudget) release(n int64) {
+	b.mu.Lock()
+	b.used -= n
+	if b.released != nil {
+		close(b.released)
+		b.released = nil
+	}
+	b.mu.Unlock()
+}
+
+// reserveMemory reserves the memory of the document of the scrape from the budget, if any.
+// The canonical, fragment, AMP and render refetches reuse the reservation of the first fetch:
+// a scrape waiting for memory while holding some could wait on the others forever.
+func (scraper *Scraper) reserveMemory(ctx context.Context) error {
+	if scraper.MemoryBudget == nil || scraper.ExpandOnly || scraper.reserved > 0 {
+		return nil
+	}
+	n := scraper.MaxDocumentLength
+	if n <= 0 || n > defaultReservation {
+		n = defaultReservation
+	}
+	if err := scraper.MemoryBudget.acquire(ctx, n); err != nil {
+		return err
+	}
+	scraper.reserved += n
+	scraper.available += n
+	return nil
+}
+
+func (scraper *Scraper) releaseMemory() {
+	if scraper.MemoryBudget != nil && scraper.reserved > 0 {
+		scraper.MemoryBudget.release(scraper.reserved)
+	}
+	scraper.reserved, scraper.available = 0, 0
+}
+
+type budgetReader struct {
+	r       io.Reader
+	scraper *Scraper
+}
+
+func (r *budgetReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	s := r.scraper
+	s.available -= int64(n)
+	if s.available < 0 {
+		s.MemoryBudget.grow(-s.available)
+		s.reserved -= s.available
+		s.available = 0
+	}
+	return n, err
+}
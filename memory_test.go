@@ -0,0 +1,47 @@
+package goscraper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemoryBudgetRefetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/page" {
+			io.WriteString(w, `<html><head><link rel="canonical" href="/canonical"></head></html>`)
+			return
+		}
+		io.WriteString(w, testPage)
+	}))
+	defer srv.Close()
+
+	// each scrape reserves 1000 bytes and refetches its canonical document: holding its first
+	// reservation, it must not wait for another one
+	budget := NewMemoryBudget(1500)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u, _ := url.Parse(srv.URL + "/page")
+			scraper := &Scraper{Url: u, MaxRedirect: 5, MaxDocumentLength: 1000, MemoryBudget: budget, Timeout: 5 * time.Second}
+			doc, err := scraper.Scrape()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if doc.Preview.Title != "Title" {
+				t.Errorf("got title %q, want the canonical document's", doc.Preview.Title)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := budget.InUse(); n != 0 {
+		t.Errorf("%d bytes still in use", n)
+	}
+}
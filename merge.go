@@ -0,0 +1,135 @@
+package goscraper
+
+import (
+	"net/url"
+	"strings"
+)
+
+// MergePreviews merges the previews of the variants of a document, e.g. its canonical, AMP and
+// mobile versions, into one. The first preview is the primary one, its values win unless:
+//   - they are empty,
+//   - its title or description is cut with an ellipsis and another one is the full text,
+//   - its icon is the default /favicon.ico and another preview has a declared one.
+//
+// Images, media, feeds and profiles are merged in the order of the previews and deduplicated.
+func MergePreviews(previews ...DocumentPreview) DocumentPreview {
+	if len(previews) == 0 {
+		return DocumentPreview{Images: []string{}}
+	}
+	merged := previews[0]
+	merged.Images = nil
+	merged.ImageDetails = nil
+	// the lists are appended to, copy them not to write into the arrays of the caller
+	merged.Feeds = append([]Feed(nil), merged.Feeds...)
+	merged.Profiles = append([]string(nil), merged.Profiles...)
+	merged.PreconnectHosts = append([]string(nil), merged.PreconnectHosts...)
+	var images imageCandidates
+	for i, p := range previews {
+		merged.Title = mergeText(merged.Title, p.Title)
+		merged.Description = mergeText(merged.Description, p.Description)
+		if i == 0 {
+			images.body = append(images.body, previewImages(p)...)
+			continue
+		}
+		if len(merged.Name) == 0 || merged.Name == hostOf(merged.Link) && len(p.Name) > 0 && p.Name != hostOf(p.Link) {
+			merged.Name = p.Name
+		}
+		if len(merged.Icon) == 0 || strings.HasSuffix(merged.Icon, "/favicon.ico") && len(p.Icon) > 0 && !strings.HasSuffix(p.Icon, "/favicon.ico") {
+			merged.Icon = p.Icon
+		}
+		for _, field := range []struct {
+			dst *string
+			src string
+		}{
+			{&merged.Link, p.Link}, {&merged.ThemeColor, p.ThemeColor}, {&merged.License, p.License}, {&merged.Copyright, p.Copyright},
+			{&merged.Logo, p.Logo}, {&merged.Manifest, p.Manifest},
+			{&merged.Next, p.Next}, {&merged.Prev, p.Prev}, {&merged.AMPURL, p.AMPURL}, {&merged.MediaType, p.MediaType},
+		} {
+			if len(*field.dst) == 0 {
+				*field.dst = field.src
+			}
+		}
+		images.body = append(images.body, previewImages(p)...)
+		merged.Videos = uniqueMedia(append(append([]MediaMeta(nil), merged.Videos...), p.Videos...))
+		merged.Audios = uniqueMedia(append(append([]MediaMeta(nil), merged.Audios...), p.Audios...))
+		merged.Feeds = mergeFeeds(merged.Feeds, p.Feeds)
+		for _, profile := range p.Profiles {
+			merged.Profiles = appendUnique(merged.Profiles, profile)
+		}
+		for _, host := range p.PreconnectHosts {
+			merged.PreconnectHosts = appendUnique(merged.PreconnectHosts, host)
+		}
+		if len(p.TOC) > len(merged.TOC) {
+			merged.TOC = p.TOC
+		}
+		if merged.Size == 0 {
+			merged.Size = p.Size
+		}
+		if merged.SearchAction == nil {
+			merged.SearchAction = p.SearchAction
+		}
+		if merged.CodeSample == nil {
+			merged.CodeSample = p.CodeSample
+		}
+		if merged.Hints == nil {
+			merged.Hints = p.Hints
+		}
+		if merged.Citation == nil {
+			merged.Citation = p.Citation
+		}
+		if merged.Price == nil {
+			merged.Price = p.Price
+		}
+		// the merged preview is as outdated as its oldest part
+		if p.ExtractorVersion < merged.ExtractorVersion {
+			merged.ExtractorVersion = p.ExtractorVersion
+		}
+	}
+	merged.ImageDetails = images.list()
+	merged.Images = imageUrls(merged.ImageDetails)
+	return merged
+}
+
+func mergeText(primary, other string) string {
+	primary = strings.TrimSpace(primary)
+	if len(primary) == 0 {
+		return other
+	}
+	p := strings.TrimSuffix(strings.TrimSuffix(primary, "…"), "...")
+	if len(p) < len(primary) && len(other) > len(p) && strings.HasPrefix(strings.TrimSpace(other), strings.TrimSpace(p)) {
+		return other
+	}
+	return primary
+}
+
+func previewImages(p DocumentPreview) []Image {
+	if len(p.ImageDetails) == len(p.Images) {
+		return p.ImageDetails
+	}
+	images := make([]Image, len(p.Images))
+	for i, u := range p.Images {
+		images[i] = Image{Url: u}
+	}
+	return images
+}
+
+func mergeFeeds(feeds, others []Feed) []Feed {
+	for _, feed := range others {
+		found := false
+		for _, f := range feeds {
+			found = found || f.Url == feed.Url
+		}
+		if !found {
+			feeds = append(feeds, feed)
+		}
+	}
+	return feeds
+}
+
+func hostOf(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
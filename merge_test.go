@@ -0,0 +1,75 @@
+package goscraper
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergePreviews(t *testing.T) {
+	tests := []struct {
+		name     string
+		previews []DocumentPreview
+		want     DocumentPreview
+	}{
+		{
+			name: "none",
+			want: DocumentPreview{Images: []string{}},
+		},
+		{
+			name: "full description",
+			previews: []DocumentPreview{
+				{Title: "Title", Description: "A cut…"},
+				{Title: "Other", Description: "A cut description"},
+			},
+			want: DocumentPreview{Title: "Title", Description: "A cut description"},
+		},
+		{
+			name: "declared icon",
+			previews: []DocumentPreview{
+				{Icon: "http://example.com/favicon.ico", Link: "http://example.com/"},
+				{Icon: "http://example.com/icon.png", ThemeColor: "#fff"},
+			},
+			want: DocumentPreview{Icon: "http://example.com/icon.png", Link: "http://example.com/", ThemeColor: "#fff"},
+		},
+		{
+			name: "lists",
+			previews: []DocumentPreview{
+				{Feeds: []Feed{{Url: "http://example.com/rss"}}, Profiles: []string{"a"}, PreconnectHosts: []string{"x"}},
+				{Feeds: []Feed{{Url: "http://example.com/rss"}, {Url: "http://example.com/atom"}}, Profiles: []string{"b", "a"}, PreconnectHosts: []string{"y"}},
+			},
+			want: DocumentPreview{
+				Feeds:           []Feed{{Url: "http://example.com/rss"}, {Url: "http://example.com/atom"}},
+				Profiles:        []string{"a", "b"},
+				PreconnectHosts: []string{"x", "y"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		got := MergePreviews(tt.previews...)
+		got.Images, got.ImageDetails = tt.want.Images, tt.want.ImageDetails
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMergePreviewsCopiesLists(t *testing.T) {
+	// spare capacity, appending in place would write into these arrays
+	feeds := append(make([]Feed, 0, 4), Feed{Url: "http://example.com/rss"})
+	profiles := append(make([]string, 0, 4), "a")
+	hosts := append(make([]string, 0, 4), "x")
+	primary := DocumentPreview{Feeds: feeds, Profiles: profiles, PreconnectHosts: hosts}
+
+	MergePreviews(primary, DocumentPreview{Feeds: []Feed{{Url: "http://example.com/atom"}}, Profiles: []string{"b"}, PreconnectHosts: []string{"y"}})
+	MergePreviews(primary, DocumentPreview{Feeds: []Feed{{Url: "http://example.com/json"}}, Profiles: []string{"c"}, PreconnectHosts: []string{"z"}})
+
+	if got := feeds[:2][1]; got != (Feed{}) {
+		t.Errorf("feeds array written: %+v", got)
+	}
+	if got := profiles[:2][1]; got != "" {
+		t.Errorf("profiles array written: %q", got)
+	}
+	if got := hosts[:2][1]; got != "" {
+		t.Errorf("preconnect hosts array written: %q", got)
+	}
+}
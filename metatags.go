@@ -0,0 +1,89 @@
+package goscraper
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// MetaTags returns the Open Graph, Twitter and standard meta tags describing the preview,
+// one per line, for a page republishing the document. Only the lead image is described:
+// when a page has several og:image, the parser keeps the last one.
+func (preview DocumentPreview) MetaTags() string {
+	var b strings.Builder
+	meta := func(attr, key, content string) {
+		if len(content) == 0 {
+			return
+		}
+		b.WriteString(`<meta ` + attr + `="` + key + `" content="` + html.EscapeString(content) + `">` + "\n")
+	}
+	property := func(key, content string) { meta("property", key, content) }
+	name := func(key, content string) { meta("name", key, content) }
+	dimension := func(key string, v int) {
+		if v > 0 {
+			property(key, strconv.Itoa(v))
+		}
+	}
+
+	property("og:type", "website")
+	property("og:site_name", preview.Name)
+	property("og:title", preview.Title)
+	property("og:description", preview.Description)
+	name("description", preview.Description)
+	property("og:url", preview.Link)
+	var image, alt string
+	if len(preview.Images) > 0 {
+		image = preview.Images[0]
+		if len(preview.ImageDetails) > 0 && preview.ImageDetails[0].Url == image {
+			alt = preview.ImageDetails[0].Alt
+		}
+		property("og:image", image)
+		property("og:image:alt", alt)
+	}
+	for _, video := range preview.Videos {
+		property("og:video", video.Url)
+		property("og:video:type", video.Type)
+		dimension("og:video:width", video.Width)
+		dimension("og:video:height", video.Height)
+	}
+	for _, audio := range preview.Audios {
+		property("og:audio", audio.Url)
+		property("og:audio:type", audio.Type)
+	}
+	if preview.Price != nil {
+		property("product:price:amount", preview.Price.Amount)
+		property("product:price:currency", preview.Price.Currency)
+	}
+
+	if len(image) > 0 {
+		name("twitter:card", "summary_large_image")
+	} else {
+		name("twitter:card", "summary")
+	}
+	name("twitter:title", preview.Title)
+	name("twitter:description", preview.Description)
+	name("twitter:image", image)
+	name("twitter:image:alt", alt)
+
+	name("theme-color", preview.ThemeColor)
+	name("copyright", preview.Copyright)
+	if c := preview.Citation; c != nil {
+		name("citation_title", c.Title)
+		for _, author := range c.Authors {
+			name("citation_author", author)
+		}
+		name("citation_journal_title", c.Journal)
+		name("citation_publication_date", c.Date)
+		name("citation_doi", c.DOI)
+		name("citation_arxiv_id", c.ArXiv)
+		name("citation_pdf_url", c.PDF)
+	}
+	if len(preview.Icon) > 0 {
+		b.WriteString(`<link rel="icon" href="` + html.EscapeString(preview.Icon) + `">` + "\n")
+	}
+	if len(preview.License) > 0 {
+		b.WriteString(`<link rel="license" href="` + html.EscapeString(preview.License) + `">` + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,169 @@
+package goscraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+)
+
+// Metrics counts what scrapers do. A single Metrics is meant to be shared by the scrapers
+// of a service and read at runtime with Snapshot, it's safe for concurrent use.
+type Metrics struct {
+	scrapes   atomic.Int64
+	requests  atomic.Int64
+	bytes     atomic.Int64
+	cacheHits atomic.Int64
+	failures  [failureClasses]atomic.Int64
+	warnings  [warningKinds]atomic.Int64
+	timeouts  [timeoutPhases]atomic.Int64
+}
+
+// FailureClass is the kind of error a scrape failed with
+type FailureClass int
+
+const (
+	FailureTimeout FailureClass = iota
+	FailureNetwork
+	FailureHTTPStatus
+	FailureDisallowed
+	FailureCircuitOpen
+	FailureTooLarge
+	FailureBlocked
+	FailureDenied
+	FailureOther
+	failureClasses
+)
+
+var failureNames = [failureClasses]string{"timeout", "network", "http_status", "disallowed", "circuit_open", "too_large", "blocked", "denied", "other"}
+
+func (c FailureClass) String() string {
+	if c < 0 || c >= failureClasses {
+		return "unknown"
+	}
+	return failureNames[c]
+}
+
+// MetricsSnapshot are the values of Metrics at a point in time
+type MetricsSnapshot struct {
+	// Tenant is the name of the tenant of the metrics, see Tenant.Snapshot
+	Tenant   string `json:"tenant,omitempty"`
+	Scrapes  int64  `json:"scrapes"`
+	Requests int64  `json:"requests"`
+	// Bytes are the body bytes downloaded, before decompression
+	Bytes int64 `json:"bytes"`
+	// CacheHits are the robots.txt and site files served from the cache, see Scraper.Site
+	CacheHits int64 `json:"cache_hits"`
+	// Failures are the failed scrapes by class, see FailureClass.String
+	Failures map[string]int64 `json:"failures"`
+	// Timeouts are the scrapes failed with an ErrTimeout by phase, see TimeoutPhase.String
+	Timeouts map[string]int64 `json:"timeouts"`
+	// Warnings are the warnings of the documents scraped by kind, see WarningKind.String
+	Warnings map[string]int64 `json:"warnings"`
+}
+
+// Snapshot returns the current values of the counters
+func (m *Metrics) Snapshot() MetricsSnapshot {
+	s := MetricsSnapshot{
+		Scrapes:   m.scrapes.Load(),
+		Requests:  m.requests.Load(),
+		Bytes:     m.bytes.Load(),
+		CacheHits: m.cacheHits.Load(),
+		Failures:  map[string]int64{},
+		Timeouts:  map[string]int64{},
+		Warnings:  map[string]int64{},
+	}
+	for c := FailureClass(0); c < failureClasses; c++ {
+		s.Failures[c.String()] = m.failures[c].Load()
+	}
+	for p := TimeoutPhase(0); p < timeoutPhases; p++ {
+		s.Timeouts[p.String()] = m.timeouts[p].Load()
+	}
+	for k := WarningKind(0); k < warningKinds; k++ {
+		s.Warnings[k.String()] = m.warnings[k].Load()
+	}
+	return s
+}
+
+// the counting methods do nothing on a nil Metrics
+
+func (m *Metrics) scrape(err error) {
+	if m == nil {
+		return
+	}
+	m.scrapes.Add(1)
+	if err != nil {
+		m.failures[failureClass(err)].Add(1)
+	}
+	var timeout ErrTimeout
+	if errors.As(err, &timeout) && timeout.Phase >= 0 && timeout.Phase < timeoutPhases {
+		m.timeouts[timeout.Phase].Add(1)
+	}
+}
+
+func (m *Metrics) warn(warnings []Warning) {
+	if m == nil {
+		return
+	}
+	for _, w := range warnings {
+		if w.Kind >= 0 && w.Kind < warningKinds {
+			m.warnings[w.Kind].Add(1)
+		}
+	}
+}
+
+func (m *Metrics) request() {
+	if m != nil {
+		m.requests.Add(1)
+	}
+}
+
+func (m *Metrics) cacheHit() {
+	if m != nil {
+		m.cacheHits.Add(1)
+	}
+}
+
+func (m *Metrics) countBody(body io.ReadCloser) io.ReadCloser {
+	if m == nil {
+		return body
+	}
+	return &countingBody{ReadCloser: body, n: &m.bytes}
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+func failureClass(err error) FailureClass {
+	var status ErrHTTPStatus
+	var denied ErrDenied
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout():
+		return FailureTimeout
+	case errors.As(err, &status):
+		return FailureHTTPStatus
+	case errors.Is(err, ErrDisallowed):
+		return FailureDisallowed
+	case errors.Is(err, ErrCircuitOpen):
+		return FailureCircuitOpen
+	case errors.Is(err, ErrTooLarge):
+		return FailureTooLarge
+	case errors.Is(err, ErrBlocked) || errors.Is(err, ErrUnsafe):
+		return FailureBlocked
+	case errors.As(err, &denied):
+		return FailureDenied
+	case errors.As(err, &netErr):
+		return FailureNetwork
+	}
+	return FailureOther
+}
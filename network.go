@@ -0,0 +1,64 @@
+package goscraper
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Connection describes the connection a request of the scrape, or one of its redirects,
+// was sent on
+type Connection struct {
+	// Host is the host:port requested
+	Host string `json:"host"`
+	// RemoteAddr is the address actually dialed, e.g. one of the ips of a CDN
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	// Reused is set when the connection was kept alive from a previous request, IdleTime is
+	// how long it was idle then
+	Reused   bool          `json:"reused"`
+	IdleTime time.Duration `json:"idle_time,omitempty"`
+	// TLSVersion and TLSCipher are the negotiated version and cipher suite of https connections
+	TLSVersion string `json:"tls_version,omitempty"`
+	TLSCipher  string `json:"tls_cipher,omitempty"`
+}
+
+type connTrace struct {
+	mu    sync.Mutex
+	host  string
+	conns []Connection
+}
+
+func withConnections(req *http.Request) (*http.Request, *connTrace) {
+	t := &connTrace{}
+	trace := &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			t.mu.Lock()
+			t.host = hostPort
+			t.mu.Unlock()
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			c := Connection{Reused: info.Reused, IdleTime: info.IdleTime}
+			if info.Conn != nil {
+				c.RemoteAddr = info.Conn.RemoteAddr().String()
+				if conn, ok := info.Conn.(*tls.Conn); ok {
+					state := conn.ConnectionState()
+					c.TLSVersion = tls.VersionName(state.Version)
+					c.TLSCipher = tls.CipherSuiteName(state.CipherSuite)
+				}
+			}
+			t.mu.Lock()
+			c.Host = t.host
+			t.conns = append(t.conns, c)
+			t.mu.Unlock()
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), t
+}
+
+func (t *connTrace) list() []Connection {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Connection(nil), t.conns...)
+}
@@ -0,0 +1,71 @@
+package goscraper
+
+import (
+	"context"
+	"net/url"
+
+	"golang.org/x/net/html"
+)
+
+type paginationExtractor struct{}
+
+func (e *paginationExtractor) Token(page *Page, token html.Token) error {
+	if token.Type == html.EndTagToken || (token.Data != "link" && token.Data != "a") {
+		return nil
+	}
+	var next, prev bool
+	var href string
+	for _, attr := range token.Attr {
+		if attr.Key == "rel" {
+			next = hasRel(attr.Val, "next")
+			prev = hasRel(attr.Val, "prev", "previous")
+		}
+		if attr.Key == "href" {
+			href = attr.Val
+		}
+	}
+	if len(href) == 0 || (!next && !prev) {
+		return nil
+	}
+	hrefUrl, err := page.Resolve(href)
+	if err != nil {
+		return err
+	}
+	if next && len(page.Doc.Preview.Next) == 0 {
+		page.Doc.Preview.Next = hrefUrl
+	}
+	if prev && len(page.Doc.Preview.Prev) == 0 {
+		page.Doc.Preview.Prev = hrefUrl
+	}
+	return nil
+}
+
+func (e *paginationExtractor) Finalize(page *Page) error {
+	return nil
+}
+
+// ScrapePages scrapes the document and follows its rel="next" links, returning at most maxPages documents
+func (scraper *Scraper) ScrapePages(ctx context.Context, maxPages int) ([]*Document, error) {
+	var docs []*Document
+	visited := map[string]bool{}
+	pager := *scraper
+	for len(docs) < maxPages {
+		visited[pager.Url.String()] = true
+		doc, err := pager.ScrapeContext(ctx)
+		if err != nil {
+			return docs, err
+		}
+		docs = append(docs, doc)
+		if len(doc.Preview.Next) == 0 || visited[doc.Preview.Next] {
+			break
+		}
+		next, err := url.Parse(doc.Preview.Next)
+		if err != nil {
+			return docs, err
+		}
+		pager = *scraper
+		pager.Url = next
+		pager.EscapedFragmentUrl = nil
+	}
+	return docs, nil
+}
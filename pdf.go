@@ -0,0 +1,99 @@
+package goscraper
+
+import (
+	"bytes"
+	"encoding/hex"
+	"unicode/utf16"
+)
+
+const maxPDFLength = 32 << 20
+
+func pdfInfo(content []byte, key string) string {
+	name := []byte("/" + key)
+	for i := bytes.Index(content, name); i >= 0; {
+		rest := bytes.TrimLeft(content[i+len(name):], " \t\r\n")
+		if len(rest) > 0 {
+			switch rest[0] {
+			case '(':
+				return decodePdfText(pdfLiteral(rest[1:]))
+			case '<':
+				if end := bytes.IndexByte(rest, '>'); end > 0 {
+					b, err := hex.DecodeString(string(bytes.Join(bytes.Fields(rest[1:end]), nil)))
+					if err == nil {
+						return decodePdfText(b)
+					}
+				}
+			}
+		}
+		next := bytes.Index(content[i+len(name):], name)
+		if next < 0 {
+			break
+		}
+		i += len(name) + next
+	}
+	return ""
+}
+
+func pdfLiteral(b []byte) []byte {
+	var out []byte
+	depth := 0
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		switch c {
+		case '\\':
+			i++
+			if i >= len(b) {
+				return out
+			}
+			switch b[i] {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r', '\n':
+				// line continuation
+			default:
+				if b[i] >= '0' && b[i] <= '7' {
+					v := 0
+					for j := 0; j < 3 && i < len(b) && b[i] >= '0' && b[i] <= '7'; j++ {
+						v = v*8 + int(b[i]-'0')
+						i++
+					}
+					i--
+					out = append(out, byte(v))
+				} else {
+					out = append(out, b[i])
+				}
+			}
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			if depth == 0 {
+				return out
+			}
+			depth--
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func decodePdfText(b []byte) string {
+	if len(b) >= 2 && b[0] == 0xfe && b[1] == 0xff {
+		u := make([]uint16, 0, len(b)/2)
+		for i := 2; i+1 < len(b); i += 2 {
+			u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+		}
+		return string(utf16.Decode(u))
+	}
+	return string(b)
+}
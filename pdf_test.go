@@ -0,0 +1,99 @@
+package goscraper
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPdfInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		want    string
+	}{
+		{"literal", "<< /Title (Annual report) /Subject (Sales) >>", "Title", "Annual report"},
+		{"other key", "<< /Title (Annual report) /Subject (Sales) >>", "Subject", "Sales"},
+		{"missing", "<< /Title (Annual report) >>", "Author", ""},
+		{"whitespace", "/Title\r\n  (Spaced)", "Title", "Spaced"},
+		{"hex", "/Title <48 65 6c6c6f>", "Title", "Hello"},
+		{"utf16 hex", "/Title <FEFF00E9007400E9>", "Title", "été"},
+		{"utf16 literal", "/Title (\xfe\xff\x00H\x00i)", "Title", "Hi"},
+		{"reference skipped", "/Title 12 0 R /Info << /Title (Second) >>", "Title", "Second"},
+		{"invalid hex", "/Title <zz>", "Title", ""},
+	}
+	for _, tt := range tests {
+		if got := pdfInfo([]byte(tt.content), tt.key); got != tt.want {
+			t.Errorf("%s: pdfInfo(%q, %q) = %q, want %q", tt.name, tt.content, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestPdfLiteral(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain) rest", "plain"},
+		{"nested (parens) ok) rest", "nested (parens) ok"},
+		{`escaped \) paren)`, "escaped ) paren"},
+		{`a\nb\tc)`, "a\nb\tc"},
+		{`octal \101\102)`, "octal AB"},
+		{`short octal \7x)`, "short octal \x07x"},
+		{"line \\\ncontinued)", "line continued"},
+		{"unterminated", "unterminated"},
+		{`trailing \`, "trailing "},
+	}
+	for _, tt := range tests {
+		if got := string(pdfLiteral([]byte(tt.in))); got != tt.want {
+			t.Errorf("pdfLiteral(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestScrapeMedia(t *testing.T) {
+	tests := []struct {
+		path        string
+		contentType string
+		body        string
+		want        DocumentPreview
+	}{
+		{"/report.pdf", "application/pdf", "%PDF-1.4 << /Title (Report) /Subject (Numbers) >>",
+			DocumentPreview{Title: "Report", Description: "Numbers", MediaType: "application/pdf", Size: 49}},
+		{"/untitled.pdf", "application/pdf", "%PDF-1.4",
+			DocumentPreview{Title: "untitled.pdf", MediaType: "application/pdf", Size: 8}},
+		{"/cat.png", "image/png", "\x89PNG",
+			DocumentPreview{Title: "cat.png", MediaType: "image/png", Size: 4, Images: []string{"/cat.png"}}},
+		{"/clip.mp4", "video/mp4", "....",
+			DocumentPreview{Title: "clip.mp4", MediaType: "video/mp4", Size: 4}},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, tt := range tests {
+			if tt.path == r.URL.Path {
+				w.Header().Set("Content-Type", tt.contentType)
+				io.WriteString(w, tt.body)
+				return
+			}
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	for _, tt := range tests {
+		doc, err := Scrape(srv.URL+tt.path, 5)
+		if err != nil {
+			t.Errorf("%s: %v", tt.path, err)
+			continue
+		}
+		p := doc.Preview
+		if p.Title != tt.want.Title || p.Description != tt.want.Description || p.MediaType != tt.want.MediaType || p.Size != tt.want.Size {
+			t.Errorf("%s: got title %q, description %q, media type %q, size %d, want %q, %q, %q, %d", tt.path,
+				p.Title, p.Description, p.MediaType, p.Size, tt.want.Title, tt.want.Description, tt.want.MediaType, tt.want.Size)
+		}
+		if len(tt.want.Images) > 0 && (len(p.Images) != 1 || p.Images[0] != srv.URL+tt.want.Images[0]) {
+			t.Errorf("%s: got images %v", tt.path, p.Images)
+		}
+	}
+}
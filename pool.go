@@ -0,0 +1,51 @@
+package goscraper
+
+import (
+	"bufio"
+	"sync"
+)
+
+// the sniffing readers and the parse state are reused across scrapes to keep the
+// allocations of each scrape flat under load, the html tokenizer can't be reset
+var (
+	sniffReaders = sync.Pool{New: func() interface{} { return bufio.NewReaderSize(nil, 1024) }}
+	pages        = sync.Pool{New: func() interface{} { return &Page{} }}
+)
+
+func getSniffReader() *bufio.Reader {
+	return sniffReaders.Get().(*bufio.Reader)
+}
+
+func putSniffReader(r *bufio.Reader) {
+	// don't keep the previous body alive
+	r.Reset(nil)
+	sniffReaders.Put(r)
+}
+
+func getPage(doc *Document, scraper *Scraper) *Page {
+	page := pages.Get().(*Page)
+	page.Doc = doc
+	page.Url = scraper.Url
+	page.Base = scraper.Url
+	return page
+}
+
+// putPage resets page, keeping the capacity of its image buckets
+func putPage(page *Page) {
+	c := &page.images
+	*page = Page{images: imageCandidates{
+		og:         clearImages(c.og),
+		itemprop:   clearImages(c.itemprop),
+		hero:       clearImages(c.hero),
+		body:       clearImages(c.body),
+		background: clearImages(c.background),
+	}}
+	pages.Put(page)
+}
+
+func clearImages(images []Image) []Image {
+	for i := range images {
+		images[i] = Image{}
+	}
+	return images[:0]
+}
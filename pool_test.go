@@ -0,0 +1,26 @@
+package goscraper
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestPutPage(t *testing.T) {
+	u, _ := url.Parse("https://example.com/")
+	page := getPage(&Document{}, &Scraper{Url: u})
+	page.HeadPassed = true
+	page.ogImage = true
+	page.images.og = append(page.images.og, Image{Url: "https://example.com/a.png", Alt: "A"})
+	page.images.body = append(page.images.body, Image{Url: "https://example.com/b.png"})
+	og := page.images.og
+	putPage(page)
+	if page.Doc != nil || page.Url != nil || page.Base != nil || page.HeadPassed || page.ogImage {
+		t.Errorf("page not reset: %+v", page)
+	}
+	if len(page.Images()) != 0 {
+		t.Errorf("got images %+v", page.Images())
+	}
+	if cap(page.images.og) == 0 || og[0] != (Image{}) {
+		t.Errorf("og bucket: cap %d, first image %+v", cap(page.images.og), og[0])
+	}
+}
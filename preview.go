@@ -0,0 +1,137 @@
+package goscraper
+
+// PreviewSchema selects the schema of the preview serialized by Document.MarshalJSON
+type PreviewSchema int
+
+const (
+	// SchemaV1 is the DocumentPreview schema, the default
+	SchemaV1 PreviewSchema = iota
+	// SchemaV2 is the PreviewV2 schema
+	SchemaV2
+)
+
+// PreviewV2 is the preview with its details grouped, new fields land here while
+// DocumentPreview keeps its shape for existing users. See DocumentPreview.V2.
+type PreviewV2 struct {
+	Version          int    `json:"version"`
+	ExtractorVersion int    `json:"extractor_version,omitempty"`
+	Url              string `json:"url"`
+	SiteName         string `json:"site_name"`
+	Title            string `json:"title"`
+	Description      string `json:"description"`
+	Icon             string `json:"icon"`
+	// MediaType and Size describe documents that aren't html
+	MediaType string `json:"media_type,omitempty"`
+	Size      int64  `json:"size,omitempty"`
+
+	Images []Image      `json:"images"`
+	Videos []MediaMeta  `json:"videos,omitempty"`
+	Audios []MediaMeta  `json:"audios,omitempty"`
+	Links  PreviewLinks `json:"links"`
+
+	ThemeColor   string        `json:"theme_color,omitempty"`
+	Logo         string        `json:"logo,omitempty"`
+	License      string        `json:"license,omitempty"`
+	Copyright    string        `json:"copyright,omitempty"`
+	SearchAction *SearchAction `json:"search_action,omitempty"`
+	TOC          []Section     `json:"toc,omitempty"`
+	CodeSample   *CodeSample   `json:"code_sample,omitempty"`
+	Hints        *PageHints    `json:"hints,omitempty"`
+	Citation     *Citation     `json:"citation,omitempty"`
+	Price        *Price        `json:"price,omitempty"`
+}
+
+// PreviewLinks are the documents and sites a preview points to
+type PreviewLinks struct {
+	AMP        string   `json:"amp,omitempty"`
+	Next       string   `json:"next,omitempty"`
+	Prev       string   `json:"prev,omitempty"`
+	Feeds      []Feed   `json:"feeds,omitempty"`
+	Profiles   []string `json:"profiles,omitempty"`
+	Preconnect []string `json:"preconnect,omitempty"`
+	Manifest   string   `json:"manifest,omitempty"`
+}
+
+// V2 converts the preview to the PreviewV2 schema
+func (preview DocumentPreview) V2() PreviewV2 {
+	images := preview.ImageDetails
+	if len(images) != len(preview.Images) {
+		// built by hand without details
+		images = make([]Image, len(preview.Images))
+		for i, u := range preview.Images {
+			images[i] = Image{Url: u}
+		}
+	}
+	return PreviewV2{
+		Version:          2,
+		ExtractorVersion: preview.ExtractorVersion,
+		Url:              preview.Link,
+		SiteName:         preview.Name,
+		Title:            preview.Title,
+		Description:      preview.Description,
+		Icon:             preview.Icon,
+		MediaType:        preview.MediaType,
+		Size:             preview.Size,
+		Images:           images,
+		Videos:           preview.Videos,
+		Audios:           preview.Audios,
+		Links: PreviewLinks{
+			AMP:        preview.AMPURL,
+			Next:       preview.Next,
+			Prev:       preview.Prev,
+			Feeds:      preview.Feeds,
+			Profiles:   preview.Profiles,
+			Preconnect: preview.PreconnectHosts,
+			Manifest:   preview.Manifest,
+		},
+		ThemeColor:   preview.ThemeColor,
+		Logo:         preview.Logo,
+		License:      preview.License,
+		Copyright:    preview.Copyright,
+		SearchAction: preview.SearchAction,
+		TOC:          preview.TOC,
+		CodeSample:   preview.CodeSample,
+		Hints:        preview.Hints,
+		Citation:     preview.Citation,
+		Price:        preview.Price,
+	}
+}
+
+// V1 converts the preview back to the DocumentPreview schema
+func (preview PreviewV2) V1() DocumentPreview {
+	var images []string
+	for _, image := range preview.Images {
+		images = append(images, image.Url)
+	}
+	return DocumentPreview{
+		Icon:             preview.Icon,
+		Name:             preview.SiteName,
+		Title:            preview.Title,
+		Description:      preview.Description,
+		Images:           images,
+		ImageDetails:     preview.Images,
+		Link:             preview.Url,
+		MediaType:        preview.MediaType,
+		Size:             preview.Size,
+		PreconnectHosts:  preview.Links.Preconnect,
+		ThemeColor:       preview.ThemeColor,
+		Logo:             preview.Logo,
+		Manifest:         preview.Links.Manifest,
+		License:          preview.License,
+		Copyright:        preview.Copyright,
+		Profiles:         preview.Links.Profiles,
+		Next:             preview.Links.Next,
+		Prev:             preview.Links.Prev,
+		Feeds:            preview.Links.Feeds,
+		AMPURL:           preview.Links.AMP,
+		SearchAction:     preview.SearchAction,
+		Videos:           preview.Videos,
+		Audios:           preview.Audios,
+		TOC:              preview.TOC,
+		CodeSample:       preview.CodeSample,
+		Hints:            preview.Hints,
+		Citation:         preview.Citation,
+		Price:            preview.Price,
+		ExtractorVersion: preview.ExtractorVersion,
+	}
+}
@@ -0,0 +1,59 @@
+package goscraper
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPreviewV2(t *testing.T) {
+	preview := DocumentPreview{
+		Icon:             "https://example.com/favicon.ico",
+		Name:             "Example",
+		Title:            "Title",
+		Description:      "Description",
+		Images:           []string{"https://example.com/a.png", "https://example.com/b.png"},
+		ImageDetails:     []Image{{Url: "https://example.com/a.png", Alt: "A"}, {Url: "https://example.com/b.png"}},
+		Link:             "https://example.com/",
+		PreconnectHosts:  []string{"cdn.example.com"},
+		ThemeColor:       "#ffffff",
+		Logo:             "https://example.com/logo.png",
+		Manifest:         "https://example.com/manifest.json",
+		License:          "https://creativecommons.org/licenses/by/4.0/",
+		Profiles:         []string{"https://twitter.com/example"},
+		Next:             "https://example.com/2",
+		Feeds:            []Feed{{Url: "https://example.com/feed", Type: "rss"}},
+		AMPURL:           "https://example.com/amp",
+		SearchAction:     &SearchAction{Target: "https://example.com/search?q={q}", QueryInput: "q"},
+		Price:            &Price{Amount: "10.00", Currency: "EUR"},
+		ExtractorVersion: 3,
+	}
+	v2 := preview.V2()
+	if v2.Version != 2 || v2.Url != preview.Link || v2.SiteName != preview.Name || v2.Links.AMP != preview.AMPURL {
+		t.Errorf("got %+v", v2)
+	}
+	if got := v2.V1(); !reflect.DeepEqual(got, preview) {
+		t.Errorf("round trip: got %+v, want %+v", got, preview)
+	}
+
+	// previews built without image details
+	v2 = DocumentPreview{Images: []string{"https://example.com/a.png"}}.V2()
+	if want := []Image{{Url: "https://example.com/a.png"}}; !reflect.DeepEqual(v2.Images, want) {
+		t.Errorf("got images %+v, want %+v", v2.Images, want)
+	}
+
+	doc := Document{Preview: preview, PreviewSchema: SchemaV2}
+	for _, marshal := range []func() ([]byte, error){func() ([]byte, error) { return json.Marshal(doc) }, doc.PreviewJSON} {
+		data, err := marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `"version":2`; !strings.Contains(string(data), want) {
+			t.Errorf("got %s, want it to contain %s", data, want)
+		}
+		if want := `"links":{"amp":"https://example.com/amp"`; !strings.Contains(string(data), want) {
+			t.Errorf("got %s, want it to contain %s", data, want)
+		}
+	}
+}
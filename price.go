@@ -0,0 +1,243 @@
+package goscraper
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	priceNumberRegexp = regexp.MustCompile(`\d[\d.,'’\s\x{a0}\x{202f}]*`)
+	isoCodeRegexp     = regexp.MustCompile(`\b[A-Z]{3}\b`)
+)
+
+// currencySymbols maps currency symbols to ISO 4217 codes, longest symbols first.
+// $, ¥ and kr depend on the locale, see localCurrency.
+var currencySymbols = []struct{ symbol, code string }{
+	{"US$", "USD"}, {"C$", "CAD"}, {"CA$", "CAD"}, {"A$", "AUD"}, {"AU$", "AUD"}, {"NZ$", "NZD"},
+	{"HK$", "HKD"}, {"MX$", "MXN"}, {"R$", "BRL"}, {"S$", "SGD"}, {"zł", "PLN"}, {"Kč", "CZK"},
+	{"€", "EUR"}, {"£", "GBP"}, {"₹", "INR"}, {"₽", "RUB"}, {"₩", "KRW"}, {"₺", "TRY"},
+	{"₪", "ILS"}, {"₫", "VND"}, {"₴", "UAH"}, {"元", "CNY"}, {"円", "JPY"},
+}
+
+var localCurrency = []struct {
+	symbol string
+	codes  map[string]string
+}{
+	{"$", map[string]string{"": "USD", "ca": "CAD", "au": "AUD", "nz": "NZD", "mx": "MXN", "sg": "SGD", "hk": "HKD", "ar": "ARS", "cl": "CLP", "co": "COP"}},
+	{"¥", map[string]string{"": "JPY", "zh": "CNY", "cn": "CNY"}},
+	{"kr", map[string]string{"": "SEK", "sv": "SEK", "no": "NOK", "nb": "NOK", "nn": "NOK", "da": "DKK", "is": "ISK"}},
+}
+
+// isoCurrencies are the active ISO 4217 codes, other words of three capitals ("NEW", "FOR")
+// aren't currencies
+var isoCurrencies = map[string]bool{}
+
+func init() {
+	for _, code := range strings.Fields(`AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BHD BIF BMD BND
+		BOB BRL BSD BTN BWP BYN BZD CAD CDF CHF CLP CNY COP CRC CUP CVE CZK DJF DKK DOP DZD EGP ERN ETB
+		EUR FJD FKP GBP GEL GHS GIP GMD GNF GTQ GYD HKD HNL HTG HUF IDR ILS INR IQD IRR ISK JMD JOD JPY
+		KES KGS KHR KMF KPW KRW KWD KYD KZT LAK LBP LKR LRD LSL LYD MAD MDL MGA MKD MMK MNT MOP MRU MUR
+		MVR MWK MXN MYR MZN NAD NGN NIO NOK NPR NZD OMR PAB PEN PGK PHP PKR PLN PYG QAR RON RSD RUB RWF
+		SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS TMT TND TOP TRY TTD TWD TZS
+		UAH UGX USD UYU UZS VES VND VUV WST XAF XCD XCG XOF XPF YER ZAR ZMW ZWG`) {
+		isoCurrencies[code] = true
+	}
+}
+
+// Price is a price normalized from the way a page displays it
+type Price struct {
+	// Amount is a decimal number with a dot as decimal separator and no grouping, e.g. 1299.00
+	Amount string `json:"amount"`
+	// Currency is the ISO 4217 code of the currency, if known
+	Currency string `json:"currency,omitempty"`
+	Raw      string `json:"raw,omitempty"`
+}
+
+// Float returns the amount as a float
+func (p Price) Float() (float64, error) {
+	return strconv.ParseFloat(p.Amount, 64)
+}
+
+// ParsePrice normalizes a displayed price, e.g. "1.299,00 €" or "$1,299". lang is the
+// language of the page (e.g. en-CA) and resolves ambiguous symbols such as $ or kr.
+func ParsePrice(raw, lang string) (Price, error) {
+	number := strings.TrimSpace(priceNumberRegexp.FindString(raw))
+	if len(number) == 0 {
+		return Price{}, fmt.Errorf("goscraper: no amount in price %q", raw)
+	}
+	return Price{Amount: normalizeAmount(number), Currency: priceCurrency(raw, lang), Raw: raw}, nil
+}
+
+// normalizeAmount removes the grouping of a displayed number and uses a dot as decimal separator.
+// With both . and , the last one is the decimal separator. A lone separator groups thousands
+// when it's followed by three digits and preceded by one to three, not starting with 0: 1,299
+// but not 0.125 nor 1234.567.
+func normalizeAmount(number string) string {
+	number = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || r == '\'' || r == '’' {
+			return -1
+		}
+		return r
+	}, number)
+	number = strings.TrimRight(number, ".,")
+	decimal := -1
+	if i := strings.LastIndexAny(number, ".,"); i >= 0 {
+		sep := number[i]
+		lone := strings.Count(number, ".")+strings.Count(number, ",") == 1
+		grouping := lone && len(number)-i-1 == 3 && i <= 3 && number[0] != '0'
+		if !grouping {
+			decimal = i
+		}
+		if !lone && strings.Count(number, string(sep)) > 1 {
+			// 1.299.000 groups only
+			decimal = -1
+		}
+	}
+	var b strings.Builder
+	for i, r := range number {
+		switch {
+		case i == decimal:
+			b.WriteByte('.')
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func priceCurrency(raw, lang string) string {
+	for _, code := range isoCodeRegexp.FindAllString(raw, -1) {
+		if isoCurrencies[code] {
+			return code
+		}
+	}
+	for _, s := range currencySymbols {
+		if strings.Contains(raw, s.symbol) {
+			return s.code
+		}
+	}
+	lang = strings.ToLower(strings.Replace(lang, "_", "-", -1))
+	for _, local := range localCurrency {
+		if !strings.Contains(strings.ToLower(raw), local.symbol) {
+			continue
+		}
+		codes := local.codes
+		parts := strings.Split(lang, "-")
+		// the region is more specific than the language
+		for i := len(parts) - 1; i >= 0; i-- {
+			if code, ok := codes[parts[i]]; ok {
+				return code
+			}
+		}
+		return codes[""]
+	}
+	return ""
+}
+
+// priceExtractor reads the price of product pages from the product:price meta tags and
+// the itemprop="price" microdata, the JSON-LD offers are read by parseDocument
+type priceExtractor struct {
+	lang     string
+	price    string
+	currency string
+	// inPrice is set inside an element with itemprop="price" and no content attribute
+	inPrice bool
+}
+
+func (e *priceExtractor) Token(page *Page, token html.Token) error {
+	switch token.Type {
+	case html.StartTagToken, html.SelfClosingTagToken:
+		var itemprop, content string
+		var hasContent bool
+		for _, attr := range token.Attr {
+			switch attr.Key {
+			case "lang":
+				if token.Data == "html" {
+					e.lang = attr.Val
+				}
+			case "itemprop":
+				itemprop = cleanStr(attr.Val)
+			case "content":
+				content, hasContent = attr.Val, true
+			}
+		}
+		switch itemprop {
+		case "price":
+			if len(e.price) == 0 {
+				e.price = content
+				e.inPrice = !hasContent && token.Type == html.StartTagToken
+			}
+		case "pricecurrency":
+			if len(e.currency) == 0 {
+				e.currency = content
+			}
+		}
+	case html.TextToken:
+		if e.inPrice {
+			e.price += token.Data
+		}
+	case html.EndTagToken:
+		e.inPrice = false
+	}
+	return nil
+}
+
+func (e *priceExtractor) Finalize(page *Page) error {
+	if page.Doc.Preview.Price != nil {
+		return nil
+	}
+	raw, currency := e.price, e.currency
+	for _, prefix := range []string{"product:price:", "og:price:"} {
+		if amounts := page.Doc.Meta[prefix+"amount"]; len(raw) == 0 && len(amounts) > 0 {
+			raw = amounts[0]
+			if currencies := page.Doc.Meta[prefix+"currency"]; len(currencies) > 0 {
+				currency = currencies[0]
+			}
+		}
+	}
+	raw = strings.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil
+	}
+	price, err := ParsePrice(raw, e.lang)
+	if err != nil {
+		// not a price
+		return nil
+	}
+	if len(currency) > 0 {
+		price.Currency = strings.ToUpper(strings.TrimSpace(currency))
+	}
+	page.Doc.Preview.Price = &price
+	return nil
+}
+
+func offerPrice(node map[string]interface{}) *Price {
+	var raw string
+	switch v := node["price"].(type) {
+	case string:
+		raw = v
+	case float64:
+		raw = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil
+	}
+	// schema.org prices use a dot as decimal separator
+	price := Price{Amount: strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' || r == '.' {
+			return r
+		}
+		return -1
+	}, raw), Raw: raw}
+	if len(price.Amount) == 0 {
+		return nil
+	}
+	if currency, ok := node["priceCurrency"].(string); ok {
+		price.Currency = strings.ToUpper(currency)
+	}
+	return &price
+}
@@ -0,0 +1,65 @@
+package goscraper
+
+import "testing"
+
+func TestNormalizeAmount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"1299", "1299"},
+		{"12.50", "12.50"},
+		{"12,5", "12.5"},
+		{"1,299", "1299"},
+		{"1.299", "1299"},
+		{"0.125", "0.125"},
+		{"0,125", "0.125"},
+		{"1234.567", "1234.567"},
+		{"1,299.00", "1299.00"},
+		{"1.299,00", "1299.00"},
+		{"1.299.000", "1299000"},
+		{"1 299,00", "1299.00"},
+		{"1'299.50", "1299.50"},
+		{"1 299,50", "1299.50"},
+		{"12.", "12"},
+	}
+	for _, tt := range tests {
+		if got := normalizeAmount(tt.in); got != tt.want {
+			t.Errorf("normalizeAmount(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		raw          string
+		lang         string
+		wantAmount   string
+		wantCurrency string
+	}{
+		{"$1,299", "en", "1299", "USD"},
+		{"$1,299", "en-CA", "1299", "CAD"},
+		{"1.299,00 €", "de", "1299.00", "EUR"},
+		{"CHF 12.50", "de-CH", "12.50", "CHF"},
+		{"NOW 12.50 EUR", "", "12.50", "EUR"},
+		{"NEW 12.50", "", "12.50", ""},
+		{"ONLY 5 FOR 20 $", "", "5", "USD"},
+		{"¥1,000", "zh-CN", "1000", "CNY"},
+		{"¥1,000", "ja", "1000", "JPY"},
+		{"100 kr", "nb-NO", "100", "NOK"},
+		{"£0.99", "en-GB", "0.99", "GBP"},
+	}
+	for _, tt := range tests {
+		p, err := ParsePrice(tt.raw, tt.lang)
+		if err != nil {
+			t.Errorf("ParsePrice(%q, %q): %v", tt.raw, tt.lang, err)
+			continue
+		}
+		if p.Amount != tt.wantAmount || p.Currency != tt.wantCurrency {
+			t.Errorf("ParsePrice(%q, %q) = %s %s, want %s %s", tt.raw, tt.lang, p.Amount, p.Currency, tt.wantAmount, tt.wantCurrency)
+		}
+	}
+	if _, err := ParsePrice("free", ""); err == nil {
+		t.Errorf("ParsePrice(%q) returned no error", "free")
+	}
+}
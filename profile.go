@@ -0,0 +1,195 @@
+package goscraper
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxProfileFeeds is the number of feeds kept in a profile, sites with per-article comment
+// feeds would grow it with every scrape
+const maxProfileFeeds = 10
+
+const profileTTL = 30 * 24 * time.Hour
+
+// profileCache holds the profiles of the hosts by Scraper.cacheKey, see Scraper.Profile.
+// profileMu guards the profiles themselves, learnProfile updates them in place.
+var (
+	profileCache = newHostCache()
+	profileMu    sync.Mutex
+)
+
+// Profile are the facts about a site learned from its documents scraped, to preview its
+// other urls before they are scraped
+type Profile struct {
+	// Host is the scheme://host of the site
+	Host       string `json:"host"`
+	Name       string `json:"name,omitempty"`
+	Icon       string `json:"icon,omitempty"`
+	Logo       string `json:"logo,omitempty"`
+	ThemeColor string `json:"theme_color,omitempty"`
+	Manifest   string `json:"manifest,omitempty"`
+	Feeds      []Feed `json:"feeds,omitempty"`
+	// Language is the most frequent language of the documents of the site
+	Language string `json:"language,omitempty"`
+	// Robots are the groups of the robots.txt of the site if it's cached, see Scraper.RespectRobots
+	Robots []RobotsGroup `json:"robots,omitempty"`
+	// Site is the SiteInfo of the site if it's cached, see Scraper.ProbeSite
+	Site *SiteInfo `json:"site,omitempty"`
+	// Documents is the number of documents the profile was learned from
+	Documents int       `json:"documents"`
+	Updated   time.Time `json:"updated"`
+
+	languages map[string]int
+}
+
+// RobotsGroup are the rules of a robots.txt for some user agents
+type RobotsGroup struct {
+	Agents   []string `json:"agents"`
+	Allow    []string `json:"allow,omitempty"`
+	Disallow []string `json:"disallow,omitempty"`
+}
+
+// SiteProfile returns the profile of a site learned by the scrapers with Profile set. host is
+// a scheme://host, or a bare host whose https then http profile is returned. The profiles
+// learned by the scrapers of a Tenant are returned by Tenant.SiteProfile.
+func SiteProfile(host string) (*Profile, bool) {
+	return siteProfile("", host)
+}
+
+func siteProfile(prefix, host string) (*Profile, bool) {
+	keys := []string{prefix + "https://" + host, prefix + "http://" + host}
+	if strings.Contains(host, "://") {
+		u, err := url.Parse(host)
+		if err != nil {
+			return nil, false
+		}
+		keys = []string{prefix + siteURL(u)}
+	}
+	for _, key := range keys {
+		cached, ok := profileCache.get(key)
+		if !ok {
+			continue
+		}
+		profileMu.Lock()
+		profile := cached.(*Profile).copy()
+		profileMu.Unlock()
+
+		if entry, ok := robotsCache.get(key); ok {
+			profile.Robots = entry.(*robotsEntry).export()
+		}
+		if info, ok := siteCache.get(key); ok {
+			profile.Site = info.(*SiteInfo)
+		}
+		return profile, true
+	}
+	return nil, false
+}
+
+// Preview returns the partial preview of link, a url of the site, from what is known of the site
+func (profile *Profile) Preview(link string) DocumentPreview {
+	name := profile.Name
+	if len(name) == 0 {
+		name = hostOf(link)
+	}
+	return DocumentPreview{
+		Icon:       profile.Icon,
+		Name:       name,
+		Images:     []string{},
+		Link:       link,
+		ThemeColor: profile.ThemeColor,
+		Logo:       profile.Logo,
+		Manifest:   profile.Manifest,
+		Feeds:      profile.Feeds,
+	}
+}
+
+func learnProfile(key string, u *url.URL, doc *Document) {
+	if !isHTML(doc.Preview.MediaType) {
+		return
+	}
+	preview := &doc.Preview
+	profileMu.Lock()
+	defer profileMu.Unlock()
+	var profile *Profile
+	if cached, ok := profileCache.get(key); ok {
+		profile = cached.(*Profile)
+	} else {
+		profile = &Profile{Host: siteURL(u), languages: map[string]int{}}
+	}
+	// the latest values win, the defaults don't override what was learned
+	if len(preview.Name) > 0 && (preview.Name != u.Host || len(profile.Name) == 0) {
+		profile.Name = preview.Name
+	}
+	if len(preview.Icon) > 0 && (!strings.HasSuffix(preview.Icon, "/favicon.ico") || len(profile.Icon) == 0) {
+		profile.Icon = preview.Icon
+	}
+	for _, field := range []struct {
+		dst *string
+		src string
+	}{
+		{&profile.Logo, preview.Logo}, {&profile.ThemeColor, preview.ThemeColor}, {&profile.Manifest, preview.Manifest},
+	} {
+		if len(field.src) > 0 {
+			*field.dst = field.src
+		}
+	}
+	profile.Feeds = mergeFeeds(profile.Feeds, preview.Feeds)
+	if len(profile.Feeds) > maxProfileFeeds {
+		profile.Feeds = profile.Feeds[:maxProfileFeeds]
+	}
+	if lang := documentLanguage(doc); len(lang) > 0 {
+		profile.languages[lang]++
+		best := profile.Language
+		for l, count := range profile.languages {
+			if count > profile.languages[best] || count == profile.languages[best] && l < best {
+				best = l
+			}
+		}
+		profile.Language = best
+	}
+	profile.Documents++
+	profile.Updated = time.Now()
+	profileCache.put(key, profile, profile.Updated.Add(profileTTL))
+}
+
+func documentLanguage(doc *Document) string {
+	if doc.Content != nil && len(doc.Content.Language) > 0 {
+		return doc.Content.Language
+	}
+	for _, name := range []string{"og:locale", "language", "dc.language"} {
+		tag := strings.ToLower(strings.TrimSpace(firstMeta(doc, name)))
+		if i := strings.IndexAny(tag, "-_"); i >= 0 {
+			tag = tag[:i]
+		}
+		if len(tag) > 0 {
+			return tag
+		}
+	}
+	return ""
+}
+
+// copy returns a copy of the profile that the scrapers updating it don't change
+func (profile *Profile) copy() *Profile {
+	c := *profile
+	c.Feeds = append([]Feed(nil), profile.Feeds...)
+	c.languages = nil
+	return &c
+}
+
+func (robots *robotsEntry) export() []RobotsGroup {
+	var groups []RobotsGroup
+	for _, g := range robots.groups {
+		group := RobotsGroup{Agents: append([]string(nil), g.agents...)}
+		for _, rule := range g.rules {
+			if rule.allow {
+				group.Allow = append(group.Allow, rule.pattern)
+			} else {
+				group.Disallow = append(group.Disallow, rule.pattern)
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
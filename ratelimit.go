@@ -0,0 +1,79 @@
+package goscraper
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RateLimiter spaces the requests made to each host. A single limiter is meant to be
+// shared by all the scrapers hitting the same hosts, concurrent ones included.
+type RateLimiter struct {
+	// Interval is the minimum delay between two requests to a host
+	Interval time.Duration
+	// Jitter adds a random delay of up to Jitter to each request
+	Jitter time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]time.Time
+	// err is set when the limiter was created with an invalid rate, it fails Wait and Validate
+	err error
+}
+
+// NewRateLimiter returns a limiter allowing rps requests per second to each host. rps must be
+// a positive number, the scrapers using a limiter created with another rate fail with an
+// ErrInvalidConfig error.
+func NewRateLimiter(rps float64, jitter time.Duration) *RateLimiter {
+	l := &RateLimiter{Jitter: jitter, hosts: map[string]time.Time{}}
+	if !(rps > 0) || math.IsInf(rps, 1) {
+		l.err = fmt.Errorf("%w: rate limiter rps %v is not a positive number", ErrInvalidConfig, rps)
+		return l
+	}
+	l.Interval = time.Duration(float64(time.Second) / rps)
+	return l
+}
+
+// Wait blocks until a request can be sent to host, or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context, host string) error {
+	if l.err != nil {
+		return l.err
+	}
+	l.mu.Lock()
+	if l.hosts == nil {
+		l.hosts = map[string]time.Time{}
+	}
+	now := time.Now()
+	if len(l.hosts) > 1024 {
+		// forget the hosts that are not throttled anymore
+		for h, next := range l.hosts {
+			if next.Before(now) {
+				delete(l.hosts, h)
+			}
+		}
+	}
+	at := now
+	if next, ok := l.hosts[host]; ok && next.After(now) {
+		at = next
+	}
+	l.hosts[host] = at.Add(l.Interval)
+	l.mu.Unlock()
+
+	wait := at.Sub(now)
+	if l.Jitter > 0 {
+		wait += time.Duration(rand.Int63n(int64(l.Jitter)))
+	}
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
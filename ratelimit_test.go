@@ -0,0 +1,41 @@
+package goscraper
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiter(t *testing.T) {
+	tests := []struct {
+		rps          float64
+		wantInterval time.Duration
+		wantErr      bool
+	}{
+		{1, time.Second, false},
+		{4, 250 * time.Millisecond, false},
+		{0.5, 2 * time.Second, false},
+		{0, 0, true},
+		{-1, 0, true},
+		{math.NaN(), 0, true},
+		{math.Inf(1), 0, true},
+	}
+	u, _ := url.Parse("http://example.com/")
+	for _, tt := range tests {
+		l := NewRateLimiter(tt.rps, 0)
+		if l.Interval != tt.wantInterval {
+			t.Errorf("rps %v: got Interval %s, want %s", tt.rps, l.Interval, tt.wantInterval)
+		}
+		err := l.Wait(context.Background(), "example.com")
+		if (err != nil) != tt.wantErr || tt.wantErr && !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("rps %v: Wait returned %v", tt.rps, err)
+		}
+		err = (&Scraper{Url: u, RateLimiter: l}).Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("rps %v: Validate returned %v", tt.rps, err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package goscraper
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var ogNamespaces = []string{"http://ogp.me/ns#", "https://ogp.me/ns#", "http://ogp.me/ns", "https://ogp.me/ns"}
+
+func parsePrefixes(doc *Document, token html.Token) {
+	for _, attr := range token.Attr {
+		if attr.Key != "prefix" {
+			continue
+		}
+		fields := strings.Fields(attr.Val)
+		for i := 0; i+1 < len(fields); i += 2 {
+			if !strings.HasSuffix(fields[i], ":") {
+				// malformed declaration, resync on the next prefix
+				i--
+				continue
+			}
+			if doc.Prefixes == nil {
+				doc.Prefixes = map[string]string{}
+			}
+			doc.Prefixes[cleanStr(strings.TrimSuffix(fields[i], ":"))] = fields[i+1]
+		}
+	}
+}
+
+func ogProperty(doc *Document, property string) string {
+	i := strings.IndexByte(property, ':')
+	if i <= 0 || len(doc.Prefixes) == 0 {
+		return property
+	}
+	ns, ok := doc.Prefixes[cleanStr(property[:i])]
+	if !ok {
+		return property
+	}
+	for _, og := range ogNamespaces {
+		if ns == og {
+			return "og" + property[i:]
+		}
+	}
+	return property
+}
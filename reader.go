@@ -0,0 +1,86 @@
+package goscraper
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var emptyBlockRegexp = regexp.MustCompile(`<(p|li|h[1-6]|blockquote|figure|figcaption|strong|em|a)>\s*</(p|li|h[1-6]|blockquote|figure|figcaption|strong|em|a)>`)
+
+func readerPolicy() *Policy {
+	p := &Policy{
+		Elements: map[string][]string{
+			"a":   {"href"},
+			"img": {"src", "alt"},
+		},
+		Drop:       DefaultPolicy().Drop,
+		URLSchemes: []string{"http", "https", "mailto"},
+	}
+	for _, tag := range []string{
+		"h1", "h2", "h3", "h4", "h5", "h6", "p", "br", "hr", "ul", "ol", "li", "blockquote", "pre",
+		"code", "em", "strong", "figure", "figcaption", "table", "thead", "tbody", "tr", "th", "td",
+	} {
+		p.Elements[tag] = nil
+	}
+	return p
+}
+
+func readerHTML(page *Page, tokens []html.Token) string {
+	var raw strings.Builder
+	for _, token := range tokens {
+		switch token.Type {
+		case html.StartTagToken, html.EndTagToken, html.SelfClosingTagToken:
+			switch token.Data {
+			case "b":
+				token.Data = "strong"
+			case "i":
+				token.Data = "em"
+			case "img":
+				src := imageSrc(token.Attr)
+				if len(src) == 0 {
+					continue
+				}
+				src, err := page.Resolve(src)
+				if err != nil {
+					continue
+				}
+				token.Attr = append(withoutAttr(token.Attr, "src"), html.Attribute{Key: "src", Val: src})
+			case "a":
+				token.Attr = resolvedHref(page, token.Attr)
+			}
+		}
+		raw.WriteString(token.String())
+	}
+	reader := readerPolicy().Sanitize(raw.String())
+	for {
+		simplified := emptyBlockRegexp.ReplaceAllStringFunc(reader, func(s string) string {
+			m := emptyBlockRegexp.FindStringSubmatch(s)
+			if m[1] != m[2] {
+				return s
+			}
+			return ""
+		})
+		if simplified == reader {
+			return reader
+		}
+		reader = simplified
+	}
+}
+
+func resolvedHref(page *Page, attrs []html.Attribute) []html.Attribute {
+	for i, attr := range attrs {
+		if attr.Key != "href" {
+			continue
+		}
+		href, err := page.Resolve(strings.TrimSpace(attr.Val))
+		if err != nil {
+			return withoutAttr(attrs, "href")
+		}
+		resolved := append([]html.Attribute(nil), attrs...)
+		resolved[i].Val = href
+		return resolved
+	}
+	return attrs
+}
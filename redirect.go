@@ -0,0 +1,50 @@
+package goscraper
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Redirect is a http redirect followed while fetching a document
+type Redirect struct {
+	// Url is the url that answered with the redirect
+	Url        string `json:"url"`
+	StatusCode int    `json:"status_code"`
+}
+
+// defaultRedirects is the limit of http redirects when MaxRedirect is 0: http.Client stops
+// after 10 requests
+const defaultRedirects = 9
+
+func (scraper *Scraper) checkRedirect(req *http.Request, via []*http.Request) error {
+	limit := scraper.redirectLimit
+	if limit <= 0 {
+		limit = defaultRedirects
+	}
+	if len(via) > limit {
+		return ErrTooManyRedirects
+	}
+	if err := scraper.checkBlocklist(req.URL); err != nil {
+		return err
+	}
+	if err := scraper.checkConsent(req.Context(), req.URL); err != nil {
+		return err
+	}
+	prev := via[len(via)-1].URL
+	if scraper.SameHostRedirects && req.URL.Host != prev.Host {
+		return fmt.Errorf("%w: %s to %s", ErrRedirectRefused, prev, req.URL)
+	}
+	if scraper.SameSchemeRedirects && req.URL.Scheme != prev.Scheme {
+		return fmt.Errorf("%w: %s to %s", ErrRedirectRefused, prev, req.URL)
+	}
+	scraper.Hooks.redirect(prev, req.URL, req.Response.StatusCode)
+	return nil
+}
+
+func redirectChain(resp *http.Response) []Redirect {
+	var chain []Redirect
+	for r := resp.Request.Response; r != nil; r = r.Request.Response {
+		chain = append([]Redirect{{Url: r.Request.URL.String(), StatusCode: r.StatusCode}}, chain...)
+	}
+	return chain
+}
@@ -0,0 +1,80 @@
+package goscraper
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// redirectServer redirects /n to /n-1 down to /0, which serves testPage
+func redirectServer(requests *atomic.Int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		n, _ := strconv.Atoi(r.URL.Path[1:])
+		if n > 0 {
+			http.Redirect(w, r, "/"+strconv.Itoa(n-1), http.StatusFound)
+			return
+		}
+		io.WriteString(w, testPage)
+	}))
+}
+
+func TestRedirectLimit(t *testing.T) {
+	var requests atomic.Int32
+	srv := redirectServer(&requests)
+	defer srv.Close()
+	tests := []struct {
+		redirects   int
+		maxRedirect int
+		wantErr     error
+	}{
+		{1, 0, nil},
+		{9, 0, nil},
+		{10, 0, ErrTooManyRedirects},
+		{3, 3, nil},
+		{4, 3, ErrTooManyRedirects},
+	}
+	for _, tt := range tests {
+		doc, err := Scrape(srv.URL+"/"+strconv.Itoa(tt.redirects), tt.maxRedirect)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%d redirects, MaxRedirect %d: got error %v, want %v", tt.redirects, tt.maxRedirect, err, tt.wantErr)
+			continue
+		}
+		if err == nil && len(doc.Redirects) != tt.redirects {
+			t.Errorf("%d redirects, MaxRedirect %d: got %d redirects", tt.redirects, tt.maxRedirect, len(doc.Redirects))
+		}
+	}
+}
+
+func TestRefusedRedirectNotRetried(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, testPage)
+	}))
+	defer other.Close()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		http.Redirect(w, r, other.URL, http.StatusFound)
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	breaker := NewCircuitBreaker(1, time.Minute)
+	scraper := &Scraper{Url: u, MaxRedirect: 5, SameHostRedirects: true, Retries: 2, Backoff: time.Millisecond, Breaker: breaker}
+	// the servers listen on the same host, with another port
+	if _, err := scraper.Scrape(); !errors.Is(err, ErrRedirectRefused) {
+		t.Fatalf("got error %v, want ErrRedirectRefused", err)
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("got %d requests, want 1", n)
+	}
+	if err := breaker.allow(u.Host); err != nil {
+		t.Errorf("refused redirect opened the circuit: %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package goscraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+)
+
+// Replay extracts the document of an archived response with the options of the scraper,
+// without fetching anything: the canonical, fragment and AMP refetches are skipped.
+// See Migrate to replay the records of outdated previews.
+func (scraper *Scraper) Replay(record *ArchiveRecord) (doc *Document, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			doc, err = nil, ErrPanic{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	u, err := url.Parse(record.Url)
+	if err != nil {
+		return nil, err
+	}
+	maxRedirect := scraper.MaxRedirect
+	defer func() { scraper.MaxRedirect = maxRedirect }()
+	scraper.MaxRedirect = 0
+	scraper.startScrape()
+	scraper.startDocument(u)
+	defer scraper.releaseMemory()
+
+	resp := &http.Response{
+		StatusCode:    record.StatusCode,
+		Header:        record.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(record.Body)),
+		ContentLength: int64(len(record.Body)),
+		Request:       &http.Request{Method: "GET", URL: u, Header: http.Header{}},
+	}
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	doc, err = scraper.readDocument(resp)
+	if err != nil {
+		return nil, err
+	}
+	if err := scraper.parseDocument(context.Background(), doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// ReplayArchive replays the records of a JSONArchive or a ZstdArchive in order, calling fn with
+// the document extracted from each record or the error replaying it. It stops on the first error of fn.
+func (scraper *Scraper) ReplayArchive(r io.Reader, fn func(record *ArchiveRecord, doc *Document, err error) error) error {
+	r, closeArchive, err := decompressArchive(r)
+	if err != nil {
+		return err
+	}
+	defer closeArchive()
+	dec := json.NewDecoder(r)
+	for {
+		record := &ArchiveRecord{}
+		if err := dec.Decode(record); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		doc, err := scraper.Replay(record)
+		if err := fn(record, doc, err); err != nil {
+			return err
+		}
+	}
+}
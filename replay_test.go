@@ -0,0 +1,42 @@
+package goscraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestReplayAfterScrape(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(testPage))
+	}))
+	defer ts.Close()
+
+	u, _ := url.Parse(ts.URL + "/old")
+	scraper := &Scraper{
+		Url:         u,
+		MaxRedirect: 3,
+		Reputation: ReputationFunc(func(ctx context.Context, u *url.URL) (Verdict, error) {
+			return Verdict{Url: u.String()}, nil
+		}),
+	}
+	doc, err := scraper.ScrapeContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Redirects) == 0 || len(doc.Verdicts) == 0 || len(doc.Connections) == 0 {
+		t.Fatalf("scrape: redirects %v, verdicts %v, connections %v", doc.Redirects, doc.Verdicts, doc.Connections)
+	}
+
+	doc = parsePage(t, scraper, "http://example.com/", testPage)
+	if len(doc.Redirects) > 0 || len(doc.Verdicts) > 0 || len(doc.Connections) > 0 {
+		t.Errorf("replay kept the scrape state: redirects %v, verdicts %v, connections %v", doc.Redirects, doc.Verdicts, doc.Connections)
+	}
+}
@@ -0,0 +1,170 @@
+package goscraper
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Reputation checks urls against a reputation service, e.g. SafeBrowsing. It's called with
+// the url of the document before it's fetched, and with the final url once the http
+// redirects are followed. Implementations that would rather fail open return a zero
+// Verdict instead of their errors, which fail the scrape.
+type Reputation interface {
+	Check(ctx context.Context, u *url.URL) (Verdict, error)
+}
+
+// ReputationFunc adapts a function to the Reputation interface
+type ReputationFunc func(ctx context.Context, u *url.URL) (Verdict, error)
+
+func (f ReputationFunc) Check(ctx context.Context, u *url.URL) (Verdict, error) {
+	return f(ctx, u)
+}
+
+// Verdict is the answer of a Reputation on a url
+type Verdict struct {
+	Url string `json:"url"`
+	// Threats are the kinds of threats the url is listed for, e.g. MALWARE or
+	// SOCIAL_ENGINEERING, none when the url is safe
+	Threats []string `json:"threats,omitempty"`
+	// Source names the service that gave the verdict
+	Source string `json:"source,omitempty"`
+}
+
+// Unsafe reports whether the url is listed for a threat
+func (v Verdict) Unsafe() bool {
+	return len(v.Threats) > 0
+}
+
+// Unsafe reports whether one of the urls checked by Reputation is unsafe, clients may
+// then warn before opening the link of the preview
+func (doc *Document) Unsafe() bool {
+	for _, v := range doc.Verdicts {
+		if v.Unsafe() {
+			return true
+		}
+	}
+	return false
+}
+
+func (scraper *Scraper) checkReputation(ctx context.Context, u *url.URL) error {
+	if scraper.Reputation == nil {
+		return nil
+	}
+	for _, v := range scraper.verdicts {
+		if v.Url == u.String() {
+			return nil
+		}
+	}
+	v, err := scraper.Reputation.Check(ctx, u)
+	if err != nil {
+		return err
+	}
+	v.Url = u.String()
+	scraper.verdicts = append(scraper.verdicts, v)
+	if v.Unsafe() && scraper.BlockUnsafe {
+		return fmt.Errorf("%w: %s listed for %v", ErrUnsafe, u, v.Threats)
+	}
+	return nil
+}
+
+const safeBrowsingEndpoint = "https://safebrowsing.googleapis.com/v4/threatMatches:find"
+
+// SafeBrowsing checks urls with the Lookup API of Google Safe Browsing (v4), or a compatible service
+type SafeBrowsing struct {
+	APIKey string
+	// ClientID and ClientVersion identify the client to the service, goscraper by default
+	ClientID      string
+	ClientVersion string
+	// ThreatTypes are the lists checked, malware, social engineering, unwanted software and
+	// potentially harmful applications by default
+	ThreatTypes []string
+	// Endpoint is the url of the threatMatches:find method, the one of Google by default
+	Endpoint string
+	// Client sends the lookups, http.DefaultClient by default
+	Client *http.Client
+}
+
+type safeBrowsingRequest struct {
+	Client struct {
+		ClientID      string `json:"clientId"`
+		ClientVersion string `json:"clientVersion"`
+	} `json:"client"`
+	ThreatInfo struct {
+		ThreatTypes      []string `json:"threatTypes"`
+		PlatformTypes    []string `json:"platformTypes"`
+		ThreatEntryTypes []string `json:"threatEntryTypes"`
+		ThreatEntries    []struct {
+			Url string `json:"url"`
+		} `json:"threatEntries"`
+	} `json:"threatInfo"`
+}
+
+type safeBrowsingResponse struct {
+	Matches []struct {
+		ThreatType string `json:"threatType"`
+	} `json:"matches"`
+}
+
+func (sb *SafeBrowsing) Check(ctx context.Context, u *url.URL) (Verdict, error) {
+	var lookup safeBrowsingRequest
+	lookup.Client.ClientID = sb.ClientID
+	if len(lookup.Client.ClientID) == 0 {
+		lookup.Client.ClientID = "goscraper"
+	}
+	lookup.Client.ClientVersion = sb.ClientVersion
+	if len(lookup.Client.ClientVersion) == 0 {
+		lookup.Client.ClientVersion = "1.0"
+	}
+	lookup.ThreatInfo.ThreatTypes = sb.ThreatTypes
+	if len(lookup.ThreatInfo.ThreatTypes) == 0 {
+		lookup.ThreatInfo.ThreatTypes = []string{"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE", "POTENTIALLY_HARMFUL_APPLICATION"}
+	}
+	lookup.ThreatInfo.PlatformTypes = []string{"ANY_PLATFORM"}
+	lookup.ThreatInfo.ThreatEntryTypes = []string{"URL"}
+	lookup.ThreatInfo.ThreatEntries = append(lookup.ThreatInfo.ThreatEntries, struct {
+		Url string `json:"url"`
+	}{Url: u.String()})
+	body, err := json.Marshal(lookup)
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	endpoint := sb.Endpoint
+	if len(endpoint) == 0 {
+		endpoint = safeBrowsingEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint+"?key="+url.QueryEscape(sb.APIKey), bytes.NewReader(body))
+	if err != nil {
+		return Verdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := sb.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return Verdict{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return Verdict{}, fmt.Errorf("goscraper: safe browsing: %w", ErrHTTPStatus{Code: resp.StatusCode})
+	}
+	var matches safeBrowsingResponse
+	if err := json.NewDecoder(&maxLengthReader{r: resp.Body, n: 1 << 20}).Decode(&matches); err != nil {
+		return Verdict{}, err
+	}
+	v := Verdict{Source: "safebrowsing"}
+	seen := map[string]bool{}
+	for _, m := range matches.Matches {
+		if !seen[m.ThreatType] {
+			seen[m.ThreatType] = true
+			v.Threats = append(v.Threats, m.ThreatType)
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,151 @@
+package goscraper
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultBackoff = 500 * time.Millisecond
+	maxBackoff     = time.Minute
+)
+
+func (scraper *Scraper) do(req *http.Request) (*http.Response, error) {
+	return scraper.doWith(scraper.fetcher(), req)
+}
+
+func (scraper *Scraper) doWith(fetcher Fetcher, req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	backoff := scraper.Backoff
+	if backoff <= 0 {
+		backoff = defaultBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		if scraper.Breaker != nil {
+			if err := scraper.Breaker.allow(req.URL.Host); err != nil {
+				return nil, err
+			}
+		}
+		if scraper.RateLimiter != nil {
+			if err := scraper.RateLimiter.Wait(ctx, req.URL.Host); err != nil {
+				return nil, err
+			}
+		}
+		scraper.Hooks.request(req)
+		scraper.Metrics.request()
+		start := time.Now()
+		tracedReq, trace := traced(req)
+		tracedReq, conns := withConnections(tracedReq)
+		// the scraper's client budgets each redirect, the other fetchers are budgeted by attempt
+		cancel := func() {}
+		if !ownClient(fetcher) {
+			tracedReq, cancel = scraper.budget(tracedReq)
+		}
+		resp, err := fetcher.Do(tracedReq)
+		resp = budgeted(resp, err, cancel)
+		scraper.connections = append(scraper.connections, conns.list()...)
+		if err != nil && isTimeout(err) {
+			err = ErrTimeout{Phase: trace.current(), Url: req.URL.String(), Elapsed: time.Since(start), Err: err}
+		}
+		scraper.Hooks.response(req, resp, err, start)
+		if err == nil {
+			resp.Body = scraper.Metrics.countBody(resp.Body)
+			decompress(resp)
+			body := &timeoutBody{ReadCloser: resp.Body, url: req.URL.String(), start: start}
+			if resp.Request != nil {
+				body.url = resp.Request.URL.String()
+			}
+			resp.Body = body
+		}
+		if scraper.Breaker != nil && ctx.Err() == nil {
+			scraper.Breaker.record(req.URL.Host, hostFailure(resp, err))
+		}
+		if attempt >= scraper.Retries || ctx.Err() != nil || !transient(resp, err) {
+			return resp, err
+		}
+
+		wait := scraper.retryWait(backoff, attempt, resp)
+		// don't wait for a retry that can't complete before the deadline
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+			return resp, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (scraper *Scraper) retryWait(backoff time.Duration, attempt int, resp *http.Response) time.Duration {
+	wait := backoff
+	for i := 0; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	if resp != nil {
+		if d, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			wait = d
+		}
+	}
+	if wait > maxBackoff {
+		wait = maxBackoff
+	}
+	if scraper.Timeout > 0 && wait > scraper.Timeout {
+		wait = scraper.Timeout
+	}
+	return wait
+}
+
+func transient(resp *http.Response, err error) bool {
+	if err != nil {
+		// every *url.Error is a net.Error, the scraper's own refusals come first
+		if refused(err) {
+			return false
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr) && netErr.Timeout() ||
+			errors.Is(err, io.EOF) ||
+			errors.Is(err, io.ErrUnexpectedEOF) ||
+			errors.Is(err, syscall.ECONNRESET) ||
+			errors.Is(err, syscall.ECONNREFUSED)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// refused reports whether a request failed on the policy of the scraper rather than on the host
+func refused(err error) bool {
+	var denied ErrDenied
+	return errors.Is(err, ErrTooManyRedirects) || errors.Is(err, ErrBlocked) || errors.Is(err, ErrRedirectRefused) ||
+		errors.As(err, &denied)
+}
+
+func retryAfter(value string) (time.Duration, bool) {
+	if len(value) == 0 {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
@@ -0,0 +1,104 @@
+package goscraper
+
+import (
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestTransient(t *testing.T) {
+	urlErr := func(err error) error { return &url.Error{Op: "Get", URL: "http://example.com/", Err: err} }
+	tests := []struct {
+		name   string
+		status int
+		err    error
+		want   bool
+	}{
+		{"ok", http.StatusOK, nil, false},
+		{"not found", http.StatusNotFound, nil, false},
+		{"too many requests", http.StatusTooManyRequests, nil, true},
+		{"bad gateway", http.StatusBadGateway, nil, true},
+		{"service unavailable", http.StatusServiceUnavailable, nil, true},
+		{"internal error", http.StatusInternalServerError, nil, false},
+		{"timeout", 0, urlErr(timeoutError{}), true},
+		{"connection refused", 0, urlErr(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}), true},
+		{"connection reset", 0, urlErr(&net.OpError{Op: "read", Err: syscall.ECONNRESET}), true},
+		{"eof", 0, urlErr(io.EOF), true},
+		{"unknown host", 0, urlErr(&net.DNSError{Err: "no such host", IsNotFound: true}), false},
+		{"tls", 0, urlErr(x509.UnknownAuthorityError{}), false},
+		{"too many redirects", 0, urlErr(ErrTooManyRedirects), false},
+		{"blocked", 0, urlErr(ErrBlocked), false},
+		{"redirect refused", 0, urlErr(ErrRedirectRefused), false},
+		{"other", 0, errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		var resp *http.Response
+		if tt.err == nil {
+			resp = &http.Response{StatusCode: tt.status}
+		}
+		if got := transient(resp, tt.err); got != tt.want {
+			t.Errorf("%s: transient = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"soon", 0, false},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", 0, true},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.value)
+		if got != tt.want || ok != tt.wantOk {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
+
+func TestRetryWait(t *testing.T) {
+	tests := []struct {
+		attempt    int
+		retryAfter string
+		timeout    time.Duration
+		want       time.Duration
+	}{
+		{0, "", 0, time.Second},
+		{2, "", 0, 4 * time.Second},
+		{10, "", 0, maxBackoff},
+		{40, "", 0, maxBackoff},
+		{0, "5", 0, 5 * time.Second},
+		{0, "3600", 0, maxBackoff},
+		{0, "3600", 10 * time.Second, 10 * time.Second},
+		{3, "", 5 * time.Second, 5 * time.Second},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if len(tt.retryAfter) > 0 {
+			resp.Header.Set("Retry-After", tt.retryAfter)
+		}
+		scraper := &Scraper{Timeout: tt.timeout}
+		if got := scraper.retryWait(time.Second, tt.attempt, resp); got != tt.want {
+			t.Errorf("attempt %d, Retry-After %q, Timeout %s: got %s, want %s", tt.attempt, tt.retryAfter, tt.timeout, got, tt.want)
+		}
+	}
+}
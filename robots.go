@@ -0,0 +1,223 @@
+package goscraper
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const robotsTTL = time.Hour
+
+var robotsCache = newHostCache()
+
+type robotsEntry struct {
+	groups  []robotsGroup
+	expires time.Time
+}
+
+type robotsGroup struct {
+	agents []string
+	rules  []robotsRule
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+	re      *regexp.Regexp
+}
+
+func (scraper *Scraper) checkRobots(ctx context.Context, u *url.URL) error {
+	robots, err := scraper.robots(ctx, u)
+	if err != nil {
+		return err
+	}
+	if !robots.allowed(productToken(scraper.userAgent()), u) {
+		return fmt.Errorf("%w: %s by robots.txt", ErrDisallowed, u)
+	}
+	return nil
+}
+
+func (scraper *Scraper) cachedRobots(u *url.URL) (*robotsEntry, bool) {
+	entry, ok := robotsCache.get(scraper.cacheKey(u))
+	if !ok {
+		return nil, false
+	}
+	scraper.Metrics.cacheHit()
+	return entry.(*robotsEntry), true
+}
+
+// robots returns the robots.txt of u's host. A robots.txt that can't be fetched doesn't
+// restrict anything, and is asked for again next time.
+func (scraper *Scraper) robots(ctx context.Context, u *url.URL) (*robotsEntry, error) {
+	if entry, ok := scraper.cachedRobots(u); ok {
+		return entry, nil
+	}
+	req, err := scraper.newRequest(ctx, "GET", siteURL(u)+"/robots.txt")
+	if err != nil {
+		return nil, err
+	}
+	// robots.txt isn't a page, don't hand it to a rendering fetcher
+	resp, err := scraper.doWith(scraper.client(), req)
+	if err != nil {
+		return &robotsEntry{}, nil
+	}
+	defer resp.Body.Close()
+
+	entry := &robotsEntry{expires: time.Now().Add(robotsTTL)}
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		entry.groups = parseRobots(io.LimitReader(resp.Body, 500<<10))
+	case resp.StatusCode >= 500:
+		// the server can't tell what is allowed: assume nothing is, and ask again next time
+		return &robotsEntry{groups: []robotsGroup{{agents: []string{"*"}, rules: []robotsRule{{pattern: "/", re: robotsRegexp("/")}}}}}, nil
+	}
+	// other statuses (e.g. 404) mean there is no restriction
+
+	robotsCache.put(scraper.cacheKey(u), entry, entry.expires)
+	return entry, nil
+}
+
+func parseRobots(r io.Reader) []robotsGroup {
+	var groups []robotsGroup
+	var group *robotsGroup
+	// user-agent lines following each other share the same group
+	var inAgents bool
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		key := cleanStr(line[:i])
+		value := strings.TrimSpace(line[i+1:])
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				groups = append(groups, robotsGroup{})
+				group = &groups[len(groups)-1]
+			}
+			group.agents = append(group.agents, strings.ToLower(value))
+			inAgents = true
+		case "allow", "disallow":
+			inAgents = false
+			if group == nil {
+				continue
+			}
+			// an empty disallow allows everything
+			if len(value) == 0 {
+				continue
+			}
+			group.rules = append(group.rules, robotsRule{allow: key == "allow", pattern: value, re: robotsRegexp(value)})
+		default:
+			inAgents = false
+		}
+	}
+	return groups
+}
+
+func (robots *robotsEntry) allowed(agent string, u *url.URL) bool {
+	agent = strings.ToLower(agent)
+	var rules, wildcard []robotsRule
+	var matched bool
+	for _, group := range robots.groups {
+		for _, a := range group.agents {
+			if a == "*" {
+				wildcard = append(wildcard, group.rules...)
+			} else if a == agent {
+				rules = append(rules, group.rules...)
+				matched = true
+			}
+		}
+	}
+	if !matched {
+		rules = wildcard
+	}
+
+	p := u.EscapedPath()
+	if len(p) == 0 {
+		p = "/"
+	}
+	if len(u.RawQuery) > 0 {
+		p += "?" + u.RawQuery
+	}
+	// the longest matching rule wins, allow wins ties
+	allow := true
+	length := -1
+	for _, rule := range rules {
+		if !rule.re.MatchString(p) {
+			continue
+		}
+		if len(rule.pattern) > length || (len(rule.pattern) == length && rule.allow) {
+			allow = rule.allow
+			length = len(rule.pattern)
+		}
+	}
+	return allow
+}
+
+func robotsRegexp(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	expr := "^" + strings.Replace(regexp.QuoteMeta(pattern), `\*`, ".*", -1)
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+func noindex(value, agent string) bool {
+	value = strings.ToLower(value)
+	if i := strings.IndexByte(value, ':'); i >= 0 {
+		name := strings.TrimSpace(value[:i])
+		if !strings.Contains(name, ",") && !strings.Contains(name, " ") {
+			if name != strings.ToLower(agent) {
+				return false
+			}
+			value = value[i+1:]
+		}
+	}
+	for _, directive := range strings.Split(value, ",") {
+		switch strings.TrimSpace(directive) {
+		case "noindex", "none":
+			return true
+		}
+	}
+	return false
+}
+
+func productToken(userAgent string) string {
+	if i := strings.IndexAny(userAgent, "/ "); i >= 0 {
+		return userAgent[:i]
+	}
+	return userAgent
+}
+
+func robotsDisallowed(resp *http.Response, agent string) bool {
+	for _, value := range resp.Header.Values("X-Robots-Tag") {
+		if noindex(value, agent) {
+			return true
+		}
+	}
+	return false
+}
+
+// dryRun returns the document of a DryRun for req, a host whose robots.txt isn't cached is
+// assumed to allow it
+func (scraper *Scraper) dryRun(req *http.Request) (*Document, error) {
+	if scraper.RespectRobots {
+		if robots, ok := scraper.cachedRobots(req.URL); ok && !robots.allowed(productToken(scraper.userAgent()), req.URL) {
+			return nil, fmt.Errorf("%w: %s by robots.txt", ErrDisallowed, req.URL)
+		}
+	}
+	return &Document{Request: req, Preview: DocumentPreview{Link: req.URL.String()}}, nil
+}
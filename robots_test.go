@@ -0,0 +1,97 @@
+package goscraper
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testRobots = `# comment
+User-agent: GoScraper
+User-agent: other
+Disallow: /private
+Allow: /private/public
+Disallow: /*.pdf$
+
+User-agent: *
+Disallow: /
+Allow: /$
+Allow: /open
+Disallow:
+`
+
+func TestRobotsAllowed(t *testing.T) {
+	robots := &robotsEntry{groups: parseRobots(strings.NewReader(testRobots))}
+	tests := []struct {
+		agent string
+		path  string
+		want  bool
+	}{
+		{"GoScraper", "/", true},
+		{"goscraper", "/private", false},
+		{"GoScraper", "/private/page", false},
+		{"GoScraper", "/private/public", true},
+		{"GoScraper", "/private/public/page", true},
+		{"GoScraper", "/file.pdf", false},
+		{"GoScraper", "/file.pdf?x=1", true},
+		{"other", "/private", false},
+		{"Unknown", "/", true},
+		{"Unknown", "/page", false},
+		{"Unknown", "/open/page", true},
+		{"Unknown", "/?q=1", false},
+	}
+	for _, tt := range tests {
+		u, _ := url.Parse("http://example.com" + tt.path)
+		if got := robots.allowed(tt.agent, u); got != tt.want {
+			t.Errorf("allowed(%q, %q) = %v, want %v", tt.agent, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseRobots(t *testing.T) {
+	groups := parseRobots(strings.NewReader(testRobots))
+	if len(groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(groups))
+	}
+	if got := strings.Join(groups[0].agents, ","); got != "goscraper,other" {
+		t.Errorf("got agents %q", got)
+	}
+	if len(groups[0].rules) != 3 || len(groups[1].rules) != 3 {
+		t.Errorf("got %d and %d rules, want 3 and 3", len(groups[0].rules), len(groups[1].rules))
+	}
+}
+
+func TestNoindex(t *testing.T) {
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"noindex", true},
+		{"NOINDEX, nofollow", true},
+		{"none", true},
+		{"index, follow", false},
+		{"goscraper: noindex", true},
+		{"googlebot: noindex", false},
+		{"unavailable_after: 25 Jun 2010 15:00:00 PST", false},
+	}
+	for _, tt := range tests {
+		if got := noindex(tt.value, "GoScraper"); got != tt.want {
+			t.Errorf("noindex(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRobotsUnreachable(t *testing.T) {
+	srv := httptest.NewServer(nil)
+	u, _ := url.Parse(srv.URL + "/page")
+	srv.Close()
+	scraper := &Scraper{Url: u}
+	if err := scraper.checkRobots(context.Background(), u); err != nil {
+		t.Fatalf("unreachable robots.txt: got %v, want no error", err)
+	}
+	if _, ok := scraper.cachedRobots(u); ok {
+		t.Errorf("unreachable robots.txt was cached")
+	}
+}
@@ -0,0 +1,165 @@
+package goscraper
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Sanitizer cleans the html extracted from documents before it's exposed, see Scraper.Sanitizer.
+// A bluemonday policy satisfies it.
+type Sanitizer interface {
+	Sanitize(html string) string
+}
+
+// Policy is a Sanitizer keeping the elements and attributes it allows. The content of the
+// elements it doesn't allow is kept, except for the dropped ones.
+type Policy struct {
+	// Elements are the elements allowed with their allowed attributes
+	Elements map[string][]string
+	// Drop are the elements removed with their content
+	Drop map[string]bool
+	// URLSchemes are the schemes allowed in the url attributes, relative urls are always allowed
+	URLSchemes []string
+}
+
+var urlAttributes = map[string]bool{"href": true, "src": true, "cite": true, "poster": true}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// DefaultPolicy returns the policy sanitizing main contents by default: text and structure
+// elements, links, images and tables, without scripts, styles, forms nor embeds
+func DefaultPolicy() *Policy {
+	p := &Policy{
+		Elements: map[string][]string{
+			"a":          {"href", "title"},
+			"img":        {"src", "alt", "title", "width", "height"},
+			"blockquote": {"cite"},
+			"q":          {"cite"},
+			"td":         {"colspan", "rowspan"},
+			"th":         {"colspan", "rowspan", "scope"},
+			"ol":         {"start", "reversed"},
+			"code":       {"class"},
+			"time":       {"datetime"},
+		},
+		Drop: map[string]bool{
+			"script": true, "style": true, "noscript": true, "template": true, "iframe": true,
+			"object": true, "embed": true, "form": true, "input": true, "button": true,
+			"select": true, "textarea": true, "svg": true, "math": true, "canvas": true,
+		},
+		URLSchemes: []string{"http", "https", "mailto"},
+	}
+	for _, tag := range []string{
+		"p", "br", "hr", "h1", "h2", "h3", "h4", "h5", "h6", "div", "span", "section", "article",
+		"header", "ul", "li", "dl", "dt", "dd", "pre", "em", "strong", "b", "i", "u", "s",
+		"sub", "sup", "small", "mark", "abbr", "cite", "kbd", "samp", "var", "del", "ins",
+		"figure", "figcaption", "picture", "table", "thead", "tbody", "tfoot", "tr", "caption",
+	} {
+		p.Elements[tag] = nil
+	}
+	return p
+}
+
+// Sanitize returns s keeping only what the policy allows, with all the elements closed
+func (p *Policy) Sanitize(s string) string {
+	var b strings.Builder
+	t := html.NewTokenizer(strings.NewReader(s))
+	var open []string
+	// dropped counts the open elements removed with their content
+	dropped := 0
+	for {
+		tokenType := t.Next()
+		if tokenType == html.ErrorToken {
+			if t.Err() != io.EOF {
+				return ""
+			}
+			break
+		}
+		token := t.Token()
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if p.Drop[token.Data] {
+				if tokenType == html.StartTagToken && !voidElements[token.Data] {
+					dropped++
+				}
+				continue
+			}
+			allowed, ok := p.Elements[token.Data]
+			if !ok || dropped > 0 {
+				continue
+			}
+			token.Attr = p.attributes(token.Attr, allowed)
+			if voidElements[token.Data] {
+				token.Type = html.SelfClosingTagToken
+			} else {
+				token.Type = html.StartTagToken
+				open = append(open, token.Data)
+			}
+			b.WriteString(token.String())
+		case html.EndTagToken:
+			if p.Drop[token.Data] {
+				if dropped > 0 {
+					dropped--
+				}
+				continue
+			}
+			if dropped > 0 {
+				continue
+			}
+			// close the elements left open in the one closed, ignore stray end tags
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i] == token.Data {
+					for j := len(open) - 1; j >= i; j-- {
+						b.WriteString("</" + open[j] + ">")
+					}
+					open = open[:i]
+					break
+				}
+			}
+		case html.TextToken:
+			if dropped == 0 {
+				b.WriteString(html.EscapeString(token.Data))
+			}
+		}
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+func (p *Policy) attributes(attrs []html.Attribute, allowed []string) []html.Attribute {
+	var kept []html.Attribute
+	for _, attr := range attrs {
+		if !containsKey(allowed, attr.Key) {
+			continue
+		}
+		if urlAttributes[attr.Key] && !p.allowedURL(attr.Val) {
+			continue
+		}
+		kept = append(kept, attr)
+	}
+	return kept
+}
+
+func (p *Policy) allowedURL(ref string) bool {
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return false
+	}
+	return len(u.Scheme) == 0 || containsKey(p.URLSchemes, u.Scheme)
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if isKey(k, key) {
+			return true
+		}
+	}
+	return false
+}
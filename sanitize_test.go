@@ -0,0 +1,54 @@
+package goscraper
+
+import "testing"
+
+func TestPolicySanitize(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"allowed", `<p>Hello <strong>world</strong></p>`, `<p>Hello <strong>world</strong></p>`},
+		{"unknown element kept its text", `<p><font color="red">red</font></p>`, `<p>red</p>`},
+		{"dropped with content", `<p>a<script>alert(1)</script>b</p>`, `<p>ab</p>`},
+		{"nested dropped", `<form><div><form>x</form>y</div></form>z`, `z`},
+		{"attributes filtered", `<a href="/x" onclick="evil()" title="t">l</a>`, `<a href="/x" title="t">l</a>`},
+		{"javascript url", `<a href="javascript:alert(1)">l</a>`, `<a>l</a>`},
+		{"uppercase scheme", `<a href="HTTPS://example.com/">l</a>`, `<a href="HTTPS://example.com/">l</a>`},
+		{"mailto", `<a href="mailto:a@example.com">l</a>`, `<a href="mailto:a@example.com">l</a>`},
+		{"data image", `<img src="data:image/png;base64,AA" alt="a">`, `<img alt="a"/>`},
+		{"void", `<p>a<br>b</p>`, `<p>a<br/>b</p>`},
+		{"unclosed", `<p>a<em>b`, `<p>a<em>b</em></p>`},
+		{"closed with open children", `<div><em>a</div>b`, `<div><em>a</em></div>b`},
+		{"stray end tag", `a</p>b`, `ab`},
+		{"text escaped", `a &lt;b&gt; &amp; c`, `a &lt;b&gt; &amp; c`},
+	}
+	p := DefaultPolicy()
+	for _, tt := range tests {
+		if got := p.Sanitize(tt.html); got != tt.want {
+			t.Errorf("%s: Sanitize(%q) = %q, want %q", tt.name, tt.html, got, tt.want)
+		}
+	}
+}
+
+func TestPolicySanitizeCustom(t *testing.T) {
+	p := &Policy{
+		Elements:   map[string][]string{"a": {"href"}},
+		Drop:       map[string]bool{"pre": true},
+		URLSchemes: []string{"https"},
+	}
+	tests := []struct {
+		html string
+		want string
+	}{
+		{`<p><a href="https://example.com/">l</a></p>`, `<a href="https://example.com/">l</a>`},
+		{`<a href="http://example.com/">l</a>`, `<a>l</a>`},
+		{`<a href="/relative">l</a>`, `<a href="/relative">l</a>`},
+		{`<pre>code</pre>text`, `text`},
+	}
+	for _, tt := range tests {
+		if got := p.Sanitize(tt.html); got != tt.want {
+			t.Errorf("Sanitize(%q) = %q, want %q", tt.html, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package goscraper
+
+import (
+	"net/url"
+	"strings"
+)
+
+// SearchAction is the schema.org SearchAction (Sitelinks search box) of a site
+type SearchAction struct {
+	// Target is the url template of a search, e.g. https://example.com/search?q={search_term_string}
+	Target string `json:"target"`
+	// QueryInput is the name of the placeholder replaced by the search terms
+	QueryInput string `json:"query_input"`
+}
+
+// Url returns the url searching query on the site
+func (action *SearchAction) Url(query string) string {
+	return strings.Replace(action.Target, "{"+action.QueryInput+"}", url.QueryEscape(query), -1)
+}
+
+func searchAction(node map[string]interface{}) *SearchAction {
+	var isSearch bool
+	for _, t := range jsonLDStrings(node, "@type") {
+		if t == "SearchAction" || strings.HasSuffix(t, "/SearchAction") {
+			isSearch = true
+		}
+	}
+	if !isSearch {
+		return nil
+	}
+	action := &SearchAction{}
+	switch target := node["target"].(type) {
+	case string:
+		action.Target = target
+	case map[string]interface{}:
+		if templates := jsonLDStrings(target, "urlTemplate"); len(templates) > 0 {
+			action.Target = templates[0]
+		}
+	case []interface{}:
+		for _, t := range target {
+			if s, ok := t.(string); ok {
+				action.Target = s
+				break
+			}
+		}
+	}
+	if len(action.Target) == 0 {
+		return nil
+	}
+	// "query-input": "required name=search_term_string"
+	for _, input := range append(jsonLDStrings(node, "query-input"), jsonLDStrings(node, "query")...) {
+		for _, field := range strings.Fields(input) {
+			if strings.HasPrefix(field, "name=") {
+				action.QueryInput = strings.TrimPrefix(field, "name=")
+			}
+		}
+	}
+	if len(action.QueryInput) == 0 {
+		// fall back on the first placeholder of the template
+		if i := strings.IndexByte(action.Target, '{'); i >= 0 {
+			if j := strings.IndexByte(action.Target[i:], '}'); j > 0 {
+				action.QueryInput = action.Target[i+1 : i+j]
+			}
+		}
+	}
+	return action
+}
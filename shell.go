@@ -0,0 +1,81 @@
+package goscraper
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const shellText = 200
+
+var shellRoots = map[string]bool{"root": true, "app": true, "__next": true, "__nuxt": true, "___gatsby": true, "svelte": true}
+
+type shellExtractor struct {
+	text    int
+	scripts int
+	root    bool
+	// hidden counts the open elements whose text isn't visible
+	hidden int
+}
+
+func (e *shellExtractor) Token(page *Page, token html.Token) error {
+	switch token.Type {
+	case html.StartTagToken, html.SelfClosingTagToken:
+		switch token.Data {
+		case "script":
+			e.scripts++
+		case "app-root":
+			e.root = true
+		}
+		for _, attr := range token.Attr {
+			if attr.Key == "id" && shellRoots[strings.TrimSpace(attr.Val)] {
+				e.root = true
+			}
+		}
+		if token.Type == html.StartTagToken && hiddenText(token.Data) {
+			e.hidden++
+		}
+	case html.EndTagToken:
+		if hiddenText(token.Data) && e.hidden > 0 {
+			e.hidden--
+		}
+	case html.TextToken:
+		if e.hidden == 0 {
+			e.text += len(strings.TrimSpace(token.Data))
+		}
+	}
+	return nil
+}
+
+func (e *shellExtractor) Finalize(page *Page) error {
+	page.Doc.shell = e.text < shellText && (e.root || e.scripts > 0) &&
+		len(page.Doc.Preview.Description) == 0 && !page.ogImage
+	return nil
+}
+
+func hiddenText(tag string) bool {
+	switch tag {
+	case "script", "style", "noscript", "template", "title":
+		return true
+	}
+	return false
+}
+
+func (scraper *Scraper) render(ctx context.Context, u *url.URL) (*Document, error) {
+	fetcher := scraper.Fetcher
+	defer func() { scraper.Fetcher = fetcher }()
+	scraper.Fetcher = scraper.RenderFetcher
+	scraper.startDocument(u)
+	scraper.MaxRedirect = scraper.redirectLimit
+	doc, err := scraper.getDocument(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := scraper.parseDocument(ctx, doc); err != nil {
+		return nil, err
+	}
+	doc.Rendered = true
+	return doc, nil
+}
@@ -0,0 +1,82 @@
+package goscraper
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+const shingleSize = 3
+
+// Fingerprint is the SimHash of a main content: near duplicate contents, e.g. an article
+// syndicated across sites, have fingerprints differing by a few bits only.
+// It's serialized in JSON as 16 hex digits, which javascript numbers can't hold.
+type Fingerprint uint64
+
+func simhash(words []contentWord) Fingerprint {
+	if len(words) == 0 {
+		return 0
+	}
+	var weights [64]int
+	n := len(words) - shingleSize + 1
+	if n < 1 {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		h := fnv.New64a()
+		for j := i; j < i+shingleSize && j < len(words); j++ {
+			h.Write([]byte(words[j].lower))
+			h.Write([]byte{' '})
+		}
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<uint(bit)) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+	var f Fingerprint
+	for bit, weight := range weights {
+		if weight > 0 {
+			f |= 1 << uint(bit)
+		}
+	}
+	return f
+}
+
+// Distance returns the number of bits f and g differ by
+func (f Fingerprint) Distance(g Fingerprint) int {
+	return bits.OnesCount64(uint64(f ^ g))
+}
+
+// NearDuplicate reports whether f and g are the fingerprints of near duplicate contents,
+// differing by 3 bits at most
+func (f Fingerprint) NearDuplicate(g Fingerprint) bool {
+	return f.Distance(g) <= 3
+}
+
+func (f Fingerprint) String() string {
+	return fmt.Sprintf("%016x", uint64(f))
+}
+
+func (f Fingerprint) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + f.String() + `"`), nil
+}
+
+func (f *Fingerprint) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if len(s) == 0 || s == "null" {
+		*f = 0
+		return nil
+	}
+	v, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return fmt.Errorf("goscraper: invalid fingerprint %q: %w", s, err)
+	}
+	*f = Fingerprint(v)
+	return nil
+}
@@ -0,0 +1,86 @@
+package goscraper
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestFingerprintDistance(t *testing.T) {
+	tests := []struct {
+		f, g     Fingerprint
+		distance int
+		near     bool
+	}{
+		{0, 0, 0, true},
+		{0xff, 0xff, 0, true},
+		{0, 0x7, 3, true},
+		{0, 0xf, 4, false},
+		{0, ^Fingerprint(0), 64, false},
+	}
+	for _, tt := range tests {
+		if got := tt.f.Distance(tt.g); got != tt.distance {
+			t.Errorf("%s.Distance(%s) = %d, want %d", tt.f, tt.g, got, tt.distance)
+		}
+		if got := tt.f.NearDuplicate(tt.g); got != tt.near {
+			t.Errorf("%s.NearDuplicate(%s) = %v, want %v", tt.f, tt.g, got, tt.near)
+		}
+	}
+}
+
+func TestSimhash(t *testing.T) {
+	article := strings.Repeat("The committee published its annual report on the state of the rivers of the region, "+
+		"noting that the water quality improved in most places while some tributaries still suffer from farm runoff. ", 5)
+	tests := []struct {
+		name string
+		a, b string
+		near bool
+	}{
+		{"same", article, article, true},
+		{"case", article, strings.ToUpper(article), true},
+		{"one word changed", article, strings.Replace(article, "annual", "yearly", 1), true},
+		{"another text", article, strings.Repeat("A recipe for bread needs flour, water, salt and yeast, and a hot oven. ", 10), false},
+	}
+	for _, tt := range tests {
+		a, b := simhash(contentWords(tt.a)), simhash(contentWords(tt.b))
+		if got := a.NearDuplicate(b); got != tt.near {
+			t.Errorf("%s: %s and %s near duplicates %v, want %v (distance %d)", tt.name, a, b, got, tt.near, a.Distance(b))
+		}
+	}
+	if f := simhash(nil); f != 0 {
+		t.Errorf("simhash of no words = %s, want 0", f)
+	}
+	if f := simhash(contentWords("short")); f == 0 {
+		t.Errorf("simhash of fewer words than a shingle = 0")
+	}
+}
+
+func TestFingerprintJSON(t *testing.T) {
+	f := Fingerprint(0x0123456789abcdef)
+	data, err := json.Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"0123456789abcdef"` {
+		t.Errorf("Marshal = %s", data)
+	}
+	tests := []struct {
+		data    string
+		want    Fingerprint
+		wantErr bool
+	}{
+		{`"0123456789abcdef"`, f, false},
+		{`"ff"`, 0xff, false},
+		{`""`, 0, false},
+		{`null`, 0, false},
+		{`"xyz"`, 0, true},
+		{`"10000000000000000"`, 0, true},
+	}
+	for _, tt := range tests {
+		var got Fingerprint
+		err := json.Unmarshal([]byte(tt.data), &got)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Unmarshal(%s) = %s, %v, want %s, error %v", tt.data, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
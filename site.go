@@ -0,0 +1,222 @@
+package goscraper
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	siteTTL      = 6 * time.Hour
+	maxWellKnown = 64 << 10
+)
+
+var siteCache = newHostCache()
+
+// SiteInfo are the facts about a site read from its well-known files, see Scraper.ProbeSite
+type SiteInfo struct {
+	// Host is the scheme://host of the site
+	Host     string       `json:"host"`
+	Security *SecurityTxt `json:"security,omitempty"`
+	// Humans is the content of /humans.txt
+	Humans   string    `json:"humans,omitempty"`
+	AppLinks *AppLinks `json:"app_links,omitempty"`
+	// Fetched is when the files were fetched, the info is cached for some hours
+	Fetched time.Time `json:"fetched"`
+}
+
+// SecurityTxt are the fields of the security.txt of a site (RFC 9116)
+type SecurityTxt struct {
+	Contacts           []string `json:"contacts,omitempty"`
+	Expires            string   `json:"expires,omitempty"`
+	Encryption         []string `json:"encryption,omitempty"`
+	Policy             []string `json:"policy,omitempty"`
+	Acknowledgments    []string `json:"acknowledgments,omitempty"`
+	Hiring             []string `json:"hiring,omitempty"`
+	Canonical          []string `json:"canonical,omitempty"`
+	PreferredLanguages []string `json:"preferred_languages,omitempty"`
+}
+
+// AppLinks are the mobile apps a site associates with its urls
+type AppLinks struct {
+	// IOS are the app ids of apple-app-site-association
+	IOS []string `json:"ios,omitempty"`
+	// Android are the package names of assetlinks.json
+	Android []string `json:"android,omitempty"`
+}
+
+// Site returns the SiteInfo of the host of the scraper's url, probing its well-known files
+// unless it's cached. The files missing or failing to download are left out.
+func (scraper *Scraper) Site(ctx context.Context) (*SiteInfo, error) {
+	key := siteURL(scraper.Url)
+	if info, ok := siteCache.get(scraper.cacheKey(scraper.Url)); ok {
+		scraper.Metrics.cacheHit()
+		return info.(*SiteInfo).clone(), nil
+	}
+
+	info := &SiteInfo{Host: key, Fetched: time.Now()}
+	for _, path := range []string{"/.well-known/security.txt", "/security.txt"} {
+		if body, ok := scraper.wellKnown(ctx, key+path, "text/plain"); ok {
+			info.Security = parseSecurityTxt(body)
+			break
+		}
+	}
+	if body, ok := scraper.wellKnown(ctx, key+"/humans.txt", "text/plain"); ok {
+		info.Humans = strings.TrimSpace(string(body))
+	}
+	links := &AppLinks{}
+	if body, ok := scraper.wellKnown(ctx, key+"/.well-known/apple-app-site-association", ""); ok {
+		links.IOS = appleAppIDs(body)
+	}
+	if body, ok := scraper.wellKnown(ctx, key+"/.well-known/assetlinks.json", ""); ok {
+		links.Android = androidPackages(body)
+	}
+	if len(links.IOS) > 0 || len(links.Android) > 0 {
+		info.AppLinks = links
+	}
+	if err := ctx.Err(); err != nil {
+		// don't cache what a cancelled probe missed
+		return nil, err
+	}
+
+	siteCache.put(scraper.cacheKey(scraper.Url), info, info.Fetched.Add(siteTTL))
+	return info.clone(), nil
+}
+
+// clone copies the info so that callers can't change the cached one
+func (info *SiteInfo) clone() *SiteInfo {
+	c := *info
+	if info.Security != nil {
+		security := *info.Security
+		for _, field := range []*[]string{&security.Contacts, &security.Encryption, &security.Policy,
+			&security.Acknowledgments, &security.Hiring, &security.Canonical, &security.PreferredLanguages} {
+			*field = append([]string(nil), *field...)
+		}
+		c.Security = &security
+	}
+	if info.AppLinks != nil {
+		c.AppLinks = &AppLinks{
+			IOS:     append([]string(nil), info.AppLinks.IOS...),
+			Android: append([]string(nil), info.AppLinks.Android...),
+		}
+	}
+	return &c
+}
+
+// wellKnown returns the body of the file at u if it's found, and in mediaType when set:
+// sites answering unknown paths with an html page don't have it
+func (scraper *Scraper) wellKnown(ctx context.Context, u, mediaType string) ([]byte, bool) {
+	req, err := scraper.newRequest(ctx, "GET", u)
+	if err != nil {
+		return nil, false
+	}
+	// well-known files aren't pages, don't hand them to a rendering fetcher
+	resp, err := scraper.doWith(scraper.client(), req)
+	if err != nil {
+		return nil, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, false
+	}
+	typ, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if len(mediaType) > 0 && typ != mediaType || isHTML(typ) {
+		return nil, false
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxWellKnown))
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+func parseSecurityTxt(body []byte) *SecurityTxt {
+	s := &SecurityTxt{}
+	scanner := bufio.NewScanner(strings.NewReader(string(body)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		value := strings.TrimSpace(line[i+1:])
+		switch cleanStr(line[:i]) {
+		case "contact":
+			s.Contacts = append(s.Contacts, value)
+		case "expires":
+			s.Expires = value
+		case "encryption":
+			s.Encryption = append(s.Encryption, value)
+		case "policy":
+			s.Policy = append(s.Policy, value)
+		case "acknowledgments", "acknowledgements":
+			s.Acknowledgments = append(s.Acknowledgments, value)
+		case "hiring":
+			s.Hiring = append(s.Hiring, value)
+		case "canonical":
+			s.Canonical = append(s.Canonical, value)
+		case "preferred-languages":
+			for _, lang := range strings.Split(value, ",") {
+				if lang = strings.TrimSpace(lang); len(lang) > 0 {
+					s.PreferredLanguages = append(s.PreferredLanguages, lang)
+				}
+			}
+		}
+	}
+	return s
+}
+
+func appleAppIDs(body []byte) []string {
+	var aasa struct {
+		Applinks struct {
+			Details []struct {
+				AppID  string   `json:"appID"`
+				AppIDs []string `json:"appIDs"`
+			} `json:"details"`
+		} `json:"applinks"`
+	}
+	if json.Unmarshal(body, &aasa) != nil {
+		return nil
+	}
+	var ids []string
+	for _, d := range aasa.Applinks.Details {
+		if len(d.AppID) > 0 {
+			ids = appendUnique(ids, d.AppID)
+		}
+		for _, id := range d.AppIDs {
+			ids = appendUnique(ids, id)
+		}
+	}
+	return ids
+}
+
+func androidPackages(body []byte) []string {
+	var statements []struct {
+		Target struct {
+			Namespace   string `json:"namespace"`
+			PackageName string `json:"package_name"`
+		} `json:"target"`
+	}
+	if json.Unmarshal(body, &statements) != nil {
+		return nil
+	}
+	var packages []string
+	for _, s := range statements {
+		if s.Target.Namespace == "android_app" && len(s.Target.PackageName) > 0 {
+			packages = appendUnique(packages, s.Target.PackageName)
+		}
+	}
+	return packages
+}
+
+func siteURL(u *url.URL) string {
+	return u.Scheme + "://" + u.Host
+}
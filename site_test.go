@@ -0,0 +1,99 @@
+package goscraper
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestParseSecurityTxt(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want *SecurityTxt
+	}{
+		{
+			name: "empty",
+			want: &SecurityTxt{},
+		},
+		{
+			name: "fields",
+			body: "# security contacts\n" +
+				"Contact: mailto:security@example.com\n" +
+				"Contact: https://example.com/report\n" +
+				"Expires: 2030-01-01T00:00:00Z\n" +
+				"Encryption: https://example.com/pgp.asc\n" +
+				"Policy: https://example.com/policy\n" +
+				"Acknowledgments: https://example.com/thanks\n" +
+				"Hiring: https://example.com/jobs\n" +
+				"Canonical: https://example.com/.well-known/security.txt\n" +
+				"Preferred-Languages: en, fr,,de\n",
+			want: &SecurityTxt{
+				Contacts:           []string{"mailto:security@example.com", "https://example.com/report"},
+				Expires:            "2030-01-01T00:00:00Z",
+				Encryption:         []string{"https://example.com/pgp.asc"},
+				Policy:             []string{"https://example.com/policy"},
+				Acknowledgments:    []string{"https://example.com/thanks"},
+				Hiring:             []string{"https://example.com/jobs"},
+				Canonical:          []string{"https://example.com/.well-known/security.txt"},
+				PreferredLanguages: []string{"en", "fr", "de"},
+			},
+		},
+		{
+			name: "case, spacing and crlf",
+			body: "CONTACT:mailto:a@example.com\r\n  expires :  2030-01-01T00:00:00Z \r\nAcknowledgements: https://example.com/hof\r\n",
+			want: &SecurityTxt{
+				Contacts:        []string{"mailto:a@example.com"},
+				Expires:         "2030-01-01T00:00:00Z",
+				Acknowledgments: []string{"https://example.com/hof"},
+			},
+		},
+		{
+			name: "signed",
+			body: "-----BEGIN PGP SIGNED MESSAGE-----\nHash: SHA256\n\nContact: mailto:a@example.com\n#Contact: mailto:commented@example.com\n" +
+				"-----BEGIN PGP SIGNATURE-----\n\niQIzBAEBCAAdFiEE\n-----END PGP SIGNATURE-----\n",
+			want: &SecurityTxt{Contacts: []string{"mailto:a@example.com"}},
+		},
+		{
+			name: "html page",
+			body: "<html><head><title>Not found</title></head></html>",
+			want: &SecurityTxt{},
+		},
+	}
+	for _, tt := range tests {
+		if got := parseSecurityTxt([]byte(tt.body)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSiteCopy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/security.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, "Contact: mailto:security@example.com\n")
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	scraper := &Scraper{Url: u}
+	want := &SecurityTxt{Contacts: []string{"mailto:security@example.com"}}
+	for i := 0; i < 2; i++ {
+		info, err := scraper.Site(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(info.Security, want) {
+			t.Fatalf("call %d: got %+v, want %+v", i, info.Security, want)
+		}
+		// changing the returned info leaves the cached one alone
+		info.Security.Contacts[0] = "mailto:changed@example.com"
+		info.Security = nil
+	}
+}
@@ -0,0 +1,147 @@
+package goscraper
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+const maxInlineSVG = 32 << 10
+
+// svgNames restores the case of the svg elements and attributes the tokenizer lowercases
+var svgNames = map[string]string{}
+
+func init() {
+	for _, name := range []string{
+		"baseProfile", "clipPath", "clipPathUnits", "feBlend", "feColorMatrix", "feComposite",
+		"feFlood", "feGaussianBlur", "feMerge", "feMergeNode", "feOffset", "foreignObject",
+		"gradientTransform", "gradientUnits", "linearGradient", "markerHeight", "markerUnits",
+		"markerWidth", "maskContentUnits", "maskUnits", "pathLength", "patternContentUnits",
+		"patternTransform", "patternUnits", "preserveAspectRatio", "radialGradient", "refX", "refY",
+		"spreadMethod", "stdDeviation", "textLength", "textPath", "viewBox",
+	} {
+		svgNames[strings.ToLower(name)] = name
+	}
+}
+
+type svgExtractor struct {
+	rasterize func(svg []byte) ([]byte, string, error)
+
+	buf     bytes.Buffer
+	depth   int
+	alt     string
+	logo    bool
+	inTitle bool
+
+	svg    []byte
+	svgAlt string
+	found  bool
+	// dropped counts the svgs too large to be used
+	dropped int
+}
+
+func (e *svgExtractor) Token(page *Page, token html.Token) error {
+	if e.found {
+		return nil
+	}
+	if e.depth == 0 {
+		if token.Type != html.StartTagToken || token.Data != "svg" {
+			return nil
+		}
+		e.buf.Reset()
+		e.alt = ""
+		e.logo = false
+		for _, attr := range token.Attr {
+			switch attr.Key {
+			case "aria-label":
+				e.alt = attr.Val
+			case "class", "id":
+				e.logo = e.logo || strings.Contains(strings.ToLower(attr.Val), "logo")
+			}
+		}
+		// a standalone svg needs its namespace
+		token.Attr = append([]html.Attribute{{Key: "xmlns", Val: "http://www.w3.org/2000/svg"}}, withoutAttr(token.Attr, "xmlns")...)
+	}
+
+	switch token.Type {
+	case html.StartTagToken:
+		e.depth++
+		e.inTitle = token.Data == "title"
+	case html.EndTagToken:
+		e.depth--
+		e.inTitle = false
+	case html.TextToken:
+		if e.inTitle && len(e.alt) == 0 {
+			e.alt = strings.TrimSpace(token.Data)
+		}
+	}
+	if e.buf.Len() <= maxInlineSVG {
+		e.buf.WriteString(svgToken(token))
+	}
+	if e.depth == 0 {
+		if e.buf.Len() > maxInlineSVG {
+			e.dropped++
+		}
+		// keep the first svg unless a logo comes later
+		if e.buf.Len() <= maxInlineSVG && (e.svg == nil || e.logo) {
+			e.svg = append([]byte(nil), e.buf.Bytes()...)
+			e.svgAlt = e.alt
+			e.found = e.logo
+		}
+	}
+	return nil
+}
+
+func (e *svgExtractor) Finalize(page *Page) error {
+	c := &page.images
+	if len(c.og)+len(c.itemprop)+len(c.hero)+len(c.body)+len(c.background) > 0 {
+		return nil
+	}
+	if e.svg == nil {
+		if e.dropped > 0 {
+			page.Doc.warn(WarningImageDropped, page.Url.String(), "%d inline svg larger than %d bytes dropped", e.dropped, maxInlineSVG)
+		}
+		return nil
+	}
+	data, mediaType := e.svg, "image/svg+xml"
+	if e.rasterize != nil {
+		// keep the svg if it can't be rasterized
+		if img, t, err := e.rasterize(e.svg); err == nil {
+			data, mediaType = img, t
+		}
+	}
+	c.body = append(c.body, Image{
+		Url: "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(data),
+		Alt: e.svgAlt,
+	})
+	return nil
+}
+
+func svgToken(token html.Token) string {
+	if token.Type == html.StartTagToken || token.Type == html.EndTagToken || token.Type == html.SelfClosingTagToken {
+		if name, ok := svgNames[token.Data]; ok {
+			token.Data = name
+		}
+		attrs := make([]html.Attribute, len(token.Attr))
+		for i, attr := range token.Attr {
+			if name, ok := svgNames[attr.Key]; ok {
+				attr.Key = name
+			}
+			attrs[i] = attr
+		}
+		token.Attr = attrs
+	}
+	return token.String()
+}
+
+func withoutAttr(attrs []html.Attribute, key string) []html.Attribute {
+	var out []html.Attribute
+	for _, attr := range attrs {
+		if attr.Key != key {
+			out = append(out, attr)
+		}
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package goscraper
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Tenant scopes the shared state of the scrapers working for one customer of a service,
+// so that customers sharing the scrapers don't see nor slow down each other: the robots.txt,
+// SiteInfo and Profile caches are kept per tenant, and the shared options the tenant sets are
+// used in place of the scraper's. A tenant is meant to live as long as the service.
+type Tenant struct {
+	// Name identifies the tenant, it scopes its caches and labels its metrics
+	Name         string
+	RateLimiter  *RateLimiter
+	Breaker      *CircuitBreaker
+	Blocklist    *Blocklist
+	MemoryBudget *MemoryBudget
+	Metrics      *Metrics
+	// AllowHosts, if not empty, are the only hosts the tenant may scrape, subdomains included.
+	// The others, redirects included, fail with ErrBlocked.
+	AllowHosts []string
+}
+
+// Scraper returns a scraper of u for the tenant, set up like a scraper with its Tenant set
+func (t *Tenant) Scraper(u *url.URL, maxRedirect int) *Scraper {
+	scraper := &Scraper{Url: u, MaxRedirect: maxRedirect, Tenant: t}
+	scraper.useTenant()
+	return scraper
+}
+
+// Snapshot returns the current values of the metrics of the tenant, labelled with its name
+func (t *Tenant) Snapshot() MetricsSnapshot {
+	var s MetricsSnapshot
+	if t.Metrics != nil {
+		s = t.Metrics.Snapshot()
+	}
+	s.Tenant = t.Name
+	return s
+}
+
+// SiteProfile is like the SiteProfile function for the profiles learned by the scrapers of the tenant
+func (t *Tenant) SiteProfile(host string) (*Profile, bool) {
+	return siteProfile(t.Name+" ", host)
+}
+
+func (t *Tenant) allowed(host string) bool {
+	if len(t.AllowHosts) == 0 {
+		return true
+	}
+	host = normalizeHost(host)
+	for _, allowed := range t.AllowHosts {
+		allowed = normalizeHost(allowed)
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+func (scraper *Scraper) useTenant() {
+	t := scraper.Tenant
+	if t == nil {
+		return
+	}
+	// the options the tenant leaves unset are the scraper's
+	if t.RateLimiter != nil {
+		scraper.RateLimiter = t.RateLimiter
+	}
+	if t.Breaker != nil {
+		scraper.Breaker = t.Breaker
+	}
+	if t.Blocklist != nil {
+		scraper.Blocklist = t.Blocklist
+	}
+	if t.MemoryBudget != nil {
+		scraper.MemoryBudget = t.MemoryBudget
+	}
+	if t.Metrics != nil {
+		scraper.Metrics = t.Metrics
+	}
+}
+
+func (scraper *Scraper) cacheKey(u *url.URL) string {
+	if scraper.Tenant != nil {
+		return scraper.Tenant.Name + " " + siteURL(u)
+	}
+	return siteURL(u)
+}
+
+func (scraper *Scraper) checkTenant(u *url.URL) error {
+	if scraper.Tenant != nil && !scraper.Tenant.allowed(u.Hostname()) {
+		return fmt.Errorf("%w: %s not allowed for tenant %s", ErrBlocked, u.Hostname(), scraper.Tenant.Name)
+	}
+	return nil
+}
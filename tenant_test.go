@@ -0,0 +1,20 @@
+package goscraper
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUseTenant(t *testing.T) {
+	limiter := NewRateLimiter(10, 0)
+	budget := NewMemoryBudget(1 << 20)
+	tenant := &Tenant{Name: "tenant", Breaker: NewCircuitBreaker(5, time.Minute), Metrics: &Metrics{}}
+	scraper := &Scraper{RateLimiter: limiter, MemoryBudget: budget, Metrics: &Metrics{}, Tenant: tenant}
+	scraper.useTenant()
+	if scraper.Breaker != tenant.Breaker || scraper.Metrics != tenant.Metrics {
+		t.Errorf("the options set by the tenant aren't used")
+	}
+	if scraper.RateLimiter != limiter || scraper.MemoryBudget != budget || scraper.Blocklist != nil {
+		t.Errorf("the options left unset by the tenant don't keep the scraper's")
+	}
+}
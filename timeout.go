@@ -0,0 +1,163 @@
+package goscraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TimeoutPhase is the phase of a request that timed out
+type TimeoutPhase int
+
+const (
+	// TimeoutDNS: resolving the host
+	TimeoutDNS TimeoutPhase = iota
+	// TimeoutConnect: opening the connection
+	TimeoutConnect
+	// TimeoutTLS: the TLS handshake
+	TimeoutTLS
+	// TimeoutHeader: waiting for the response headers once the request is sent, or for the
+	// response of a Fetcher that doesn't report the phases, e.g. a browser
+	TimeoutHeader
+	// TimeoutBody: reading the body
+	TimeoutBody
+	timeoutPhases
+)
+
+var timeoutNames = [timeoutPhases]string{"dns", "connect", "tls", "header", "body"}
+
+func (p TimeoutPhase) String() string {
+	if p < 0 || p >= timeoutPhases {
+		return "unknown"
+	}
+	return timeoutNames[p]
+}
+
+// ErrTimeout is returned when a request times out, whether Scraper.Timeout expired or the
+// Fetcher gave up, with the phase it was in and the time since the request was sent.
+// It wraps the error of the Fetcher.
+type ErrTimeout struct {
+	Phase   TimeoutPhase
+	Url     string
+	Elapsed time.Duration
+	Err     error
+}
+
+func (e ErrTimeout) Error() string {
+	return fmt.Sprintf("goscraper: %s timeout after %s: %s", e.Phase, e.Elapsed.Round(time.Millisecond), e.Url)
+}
+
+func (e ErrTimeout) Unwrap() error {
+	return e.Err
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// phaseTrace follows the phase of a request, the trace hooks may be called concurrently
+type phaseTrace struct {
+	mu    sync.Mutex
+	phase TimeoutPhase
+}
+
+func (t *phaseTrace) set(phase TimeoutPhase) {
+	t.mu.Lock()
+	t.phase = phase
+	t.mu.Unlock()
+}
+
+func (t *phaseTrace) current() TimeoutPhase {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.phase
+}
+
+func traced(req *http.Request) (*http.Request, *phaseTrace) {
+	t := &phaseTrace{phase: TimeoutHeader}
+	trace := &httptrace.ClientTrace{
+		GetConn:           func(string) { t.set(TimeoutConnect) },
+		DNSStart:          func(httptrace.DNSStartInfo) { t.set(TimeoutDNS) },
+		DNSDone:           func(httptrace.DNSDoneInfo) { t.set(TimeoutConnect) },
+		ConnectStart:      func(string, string) { t.set(TimeoutConnect) },
+		TLSHandshakeStart: func() { t.set(TimeoutTLS) },
+		GotConn:           func(httptrace.GotConnInfo) { t.set(TimeoutHeader) },
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace)), t
+}
+
+type timeoutBody struct {
+	io.ReadCloser
+	url   string
+	start time.Time
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && isTimeout(err) {
+		err = ErrTimeout{Phase: TimeoutBody, Url: b.url, Elapsed: time.Since(b.start), Err: err}
+	}
+	return n, err
+}
+
+// budget bounds req to its share of the time left before the deadline of its context, the
+// n-th request of the scrape getting 1/n of it. The returned cancel releases the budget.
+func (scraper *Scraper) budget(req *http.Request) (*http.Request, context.CancelFunc) {
+	n := atomic.AddInt32(&scraper.requests, 1)
+	deadline, ok := req.Context().Deadline()
+	if !ok {
+		return req, func() {}
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), time.Until(deadline)/time.Duration(n))
+	return req.WithContext(ctx), cancel
+}
+
+// budgeted releases the budget of a request once its body is closed, or at once if it failed
+func budgeted(resp *http.Response, err error, cancel context.CancelFunc) *http.Response {
+	if err != nil || resp == nil {
+		cancel()
+		return resp
+	}
+	resp.Body = &budgetBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp
+}
+
+type budgetBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *budgetBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// budgetTransport budgets each request of the scraper's client, redirects included
+type budgetTransport struct {
+	scraper *Scraper
+	base    http.RoundTripper
+}
+
+func (t *budgetTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req, cancel := t.scraper.budget(req)
+	resp, err := t.base.RoundTrip(req)
+	return budgeted(resp, err, cancel), err
+}
+
+func ownClient(fetcher Fetcher) bool {
+	client, ok := fetcher.(*http.Client)
+	if !ok {
+		return false
+	}
+	_, ok = client.Transport.(*budgetTransport)
+	return ok
+}
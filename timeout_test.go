@@ -0,0 +1,63 @@
+package goscraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	scraper := &Scraper{}
+	req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com/", nil)
+	for n, want := range []time.Duration{time.Second, time.Second / 2, time.Second / 3} {
+		r, release := scraper.budget(req)
+		deadline, _ := r.Context().Deadline()
+		release()
+		// the share is taken of what is left, a little less than the second
+		if got := time.Until(deadline); got > want || got < want-100*time.Millisecond {
+			t.Errorf("request %d: budget %s, want about %s", n+1, got, want)
+		}
+	}
+
+	scraper = &Scraper{}
+	req, _ = http.NewRequest("GET", "http://example.com/", nil)
+	if r, release := scraper.budget(req); r != req {
+		t.Errorf("request without deadline budgeted")
+	} else {
+		release()
+	}
+}
+
+func TestRedirectBudget(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/slow", http.StatusFound)
+			return
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		io.WriteString(w, testPage)
+	}))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL)
+	start := time.Now()
+	_, err := (&Scraper{Url: u, MaxRedirect: 5, Timeout: time.Second}).Scrape()
+	var timeout ErrTimeout
+	if !errors.As(err, &timeout) {
+		t.Fatalf("got error %v, want ErrTimeout", err)
+	}
+	// the redirected request only gets half of what the first one left
+	if elapsed := time.Since(start); elapsed > 800*time.Millisecond {
+		t.Errorf("redirect timed out after %s, want about 500ms", elapsed)
+	}
+}
@@ -0,0 +1,123 @@
+package goscraper
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var tocRegexp = regexp.MustCompile(`(?i)\btoc\b|table-of-contents|on-this-page`)
+
+// Section is an entry of the table of contents of a document
+type Section struct {
+	Title string `json:"title"`
+	Url   string `json:"url"`
+	// Level is the nesting of the section, starting at 1
+	Level int `json:"level"`
+}
+
+type tocExtractor struct {
+	// container is the tag of the toc candidate being read, nested is the count of open
+	// elements of the same tag in it
+	container string
+	nested    int
+	marked    bool
+	lists     int
+	sections  []Section
+	link      *Section
+
+	toc  []Section
+	done bool
+}
+
+func (e *tocExtractor) Token(page *Page, token html.Token) error {
+	if e.done {
+		return nil
+	}
+	if len(e.container) == 0 {
+		if token.Type != html.StartTagToken {
+			return nil
+		}
+		marked := false
+		for _, attr := range token.Attr {
+			switch attr.Key {
+			case "class", "id":
+				marked = marked || tocRegexp.MatchString(attr.Val)
+			}
+		}
+		// a nav isn't a toc once a marked one is found, keep looking for the latter
+		if marked || (token.Data == "nav" || token.Data == "aside") && len(e.toc) == 0 {
+			e.container = token.Data
+			e.marked = marked
+			e.nested = 0
+			e.lists = 0
+			e.sections = nil
+		}
+		return nil
+	}
+
+	switch token.Type {
+	case html.StartTagToken:
+		switch token.Data {
+		case e.container:
+			e.nested++
+		case "ul", "ol":
+			e.lists++
+		case "a":
+			for _, attr := range token.Attr {
+				if attr.Key != "href" {
+					continue
+				}
+				href := strings.TrimSpace(attr.Val)
+				if !e.marked && !strings.HasPrefix(href, "#") || len(href) < 2 {
+					break
+				}
+				u, err := page.Resolve(href)
+				if err != nil {
+					return err
+				}
+				level := e.lists
+				if level == 0 {
+					level = 1
+				}
+				e.link = &Section{Url: u, Level: level}
+			}
+		}
+	case html.TextToken:
+		if e.link != nil {
+			e.link.Title += token.Data
+		}
+	case html.EndTagToken:
+		switch token.Data {
+		case e.container:
+			if e.nested > 0 {
+				e.nested--
+				break
+			}
+			e.container = ""
+			if len(e.sections) >= 2 {
+				e.toc = e.sections
+				e.done = e.marked
+			}
+		case "ul", "ol":
+			if e.lists > 0 {
+				e.lists--
+			}
+		case "a":
+			if e.link != nil {
+				e.link.Title = strings.Join(strings.Fields(e.link.Title), " ")
+				if len(e.link.Title) > 0 {
+					e.sections = append(e.sections, *e.link)
+				}
+				e.link = nil
+			}
+		}
+	}
+	return nil
+}
+
+func (e *tocExtractor) Finalize(page *Page) error {
+	page.Doc.Preview.TOC = e.toc
+	return nil
+}
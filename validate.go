@@ -0,0 +1,137 @@
+package goscraper
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// Validate checks that the options of the scraper are consistent, so that a misconfigured
+// scraper fails at startup rather than on every scrape. All the problems found are reported
+// in a single ErrInvalidConfig error.
+func (scraper *Scraper) Validate() error {
+	var problems []string
+	fail := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if scraper.Url == nil {
+		fail("no url")
+	} else if scraper.Url.Scheme != "http" && scraper.Url.Scheme != "https" || len(scraper.Url.Host) == 0 {
+		fail("url %q is not an absolute http or https url", scraper.Url)
+	}
+	for name, value := range map[string]int64{
+		"MaxRedirect":       int64(scraper.MaxRedirect),
+		"MaxDocumentLength": scraper.MaxDocumentLength,
+		"Retries":           int64(scraper.Retries),
+		"Backoff":           int64(scraper.Backoff),
+		"Timeout":           int64(scraper.Timeout),
+		"FallbackLength":    int64(scraper.FallbackLength),
+		"ChunkSize":         int64(scraper.ChunkSize),
+		"Keywords":          int64(scraper.Keywords),
+	} {
+		if value < 0 {
+			fail("negative %s", name)
+		}
+	}
+	if scraper.Retries > 0 && scraper.Timeout > 0 && scraper.Backoff >= scraper.Timeout {
+		fail("Backoff %s leaves no time to retry within Timeout %s", scraper.Backoff, scraper.Timeout)
+	}
+
+	if ua := scraper.userAgent(); !httpguts.ValidHeaderFieldValue(ua) || strings.TrimSpace(ua) != ua || len(productToken(ua)) == 0 {
+		fail("invalid user agent %q", ua)
+	}
+	for key, values := range scraper.Header {
+		if !httpguts.ValidHeaderFieldName(key) {
+			fail("invalid header name %q", key)
+		}
+		for _, v := range values {
+			if !httpguts.ValidHeaderFieldValue(v) {
+				fail("invalid value %q of header %s", v, key)
+			}
+		}
+	}
+	if len(scraper.Password) > 0 && len(scraper.Username) == 0 {
+		fail("Password without Username")
+	}
+
+	if scraper.ExpandOnly && scraper.Streaming {
+		fail("ExpandOnly doesn't download the document to stream")
+	}
+	if scraper.HeadOnly {
+		for name, enabled := range map[string]bool{
+			"BackgroundImages": scraper.BackgroundImages,
+			"InlineSVG":        scraper.InlineSVG,
+			"TableOfContents":  scraper.TableOfContents,
+			"CodeSamples":      scraper.CodeSamples,
+			"Hints":            scraper.Hints,
+			"MainContent":      scraper.MainContent,
+			"ContentFallback":  scraper.ContentFallback,
+		} {
+			if enabled {
+				fail("HeadOnly ignores the body %s reads", name)
+			}
+		}
+	}
+	if scraper.RasterizeSVG != nil && !scraper.InlineSVG {
+		fail("RasterizeSVG without InlineSVG")
+	}
+	if scraper.Breaker != nil && (scraper.Breaker.Threshold <= 0 || scraper.Breaker.Cooldown <= 0) {
+		fail("circuit breaker without Threshold or Cooldown")
+	}
+	if scraper.RateLimiter != nil {
+		if scraper.RateLimiter.err != nil {
+			fail("rate limiter rps is not a positive number")
+		}
+		if scraper.RateLimiter.Interval < 0 || scraper.RateLimiter.Jitter < 0 {
+			fail("negative rate limiter Interval or Jitter")
+		}
+	}
+	if scraper.MemoryBudget != nil && scraper.MemoryBudget.Limit <= 0 {
+		fail("memory budget without Limit")
+	}
+	if scraper.Sanitizer != nil && !scraper.MainContent {
+		fail("Sanitizer without MainContent")
+	}
+	if scraper.ChunkSize != 0 && !scraper.MainContent {
+		fail("ChunkSize without MainContent")
+	}
+	if scraper.Keywords != 0 && !scraper.MainContent {
+		fail("Keywords without MainContent")
+	}
+	if scraper.BlockUnsafe && scraper.Reputation == nil {
+		fail("BlockUnsafe without Reputation")
+	}
+	if scraper.Blocklist != nil && len(scraper.Blocklist.Sources) == 0 {
+		fail("blocklist without Sources")
+	}
+	if scraper.Tenant != nil {
+		if len(scraper.Tenant.Name) == 0 {
+			fail("tenant without Name")
+		}
+		for _, host := range scraper.Tenant.AllowHosts {
+			if len(normalizeHost(host)) == 0 {
+				fail("invalid tenant allowed host %q", host)
+			}
+		}
+	}
+	for _, encoding := range scraper.AcceptEncodings {
+		if !httpguts.ValidHeaderFieldValue(encoding) || strings.ContainsAny(encoding, ",") {
+			fail("invalid accepted encoding %q", encoding)
+		}
+	}
+	for i, newExtractor := range scraper.Extractors {
+		if newExtractor == nil {
+			fail("nil Extractors[%d]", i)
+		}
+	}
+
+	if len(problems) > 0 {
+		// sorted for a stable message, the limits are checked in map order
+		sort.Strings(problems)
+		return fmt.Errorf("%w: %s", ErrInvalidConfig, strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package goscraper
+
+import (
+	"context"
+	"net/url"
+)
+
+// ExtractorVersion is stamped on every preview, it's incremented by the releases changing
+// what is extracted from a document so that stored previews can be migrated:
+//
+//	1: first stamped version
+const ExtractorVersion = 1
+
+// Outdated reports whether the preview was extracted by an older version of the extractors.
+// Previews from before the versioning have no version and are outdated.
+func (preview DocumentPreview) Outdated() bool {
+	return preview.ExtractorVersion < ExtractorVersion
+}
+
+// Migrate extracts again an outdated preview with the scraper's options, from record if it's
+// the archived response of the preview, else by scraping the preview's link again.
+// Up to date previews are returned as they are.
+func (scraper *Scraper) Migrate(ctx context.Context, preview DocumentPreview, record *ArchiveRecord) (DocumentPreview, error) {
+	if !preview.Outdated() {
+		return preview, nil
+	}
+	var doc *Document
+	var err error
+	if record != nil {
+		doc, err = scraper.Replay(record)
+	} else {
+		var u *url.URL
+		u, err = url.Parse(preview.Link)
+		if err != nil {
+			return preview, err
+		}
+		scraper.Url = u
+		scraper.EscapedFragmentUrl = nil
+		doc, err = scraper.ScrapeContext(ctx)
+	}
+	if err != nil {
+		return preview, err
+	}
+	return doc.Preview, nil
+}
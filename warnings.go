@@ -0,0 +1,62 @@
+package goscraper
+
+import "fmt"
+
+// WarningKind is the kind of problem a Warning reports
+type WarningKind int
+
+const (
+	// WarningCharsetFallback: the charset declared by the document is wrong or unknown, it was
+	// decoded by sniffing its content or read as is
+	WarningCharsetFallback WarningKind = iota
+	// WarningImageDropped: an image was found but left out of the preview
+	WarningImageDropped
+	// WarningCanonicalIgnored: the canonical link isn't followed, as the redirect policy refuses it
+	WarningCanonicalIgnored
+	// WarningTruncated: the document or its main content was cut, the preview is built from
+	// what was read
+	WarningTruncated
+	warningKinds
+)
+
+var warningNames = [warningKinds]string{"charset_fallback", "image_dropped", "canonical_ignored", "truncated"}
+
+func (k WarningKind) String() string {
+	if k < 0 || k >= warningKinds {
+		return "unknown"
+	}
+	return warningNames[k]
+}
+
+// MarshalText serializes the kind by name
+func (k WarningKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText reads a kind serialized by name, the kinds it doesn't know are unknown
+func (k *WarningKind) UnmarshalText(text []byte) error {
+	*k = -1
+	for i, name := range warningNames {
+		if name == string(text) {
+			*k = WarningKind(i)
+		}
+	}
+	return nil
+}
+
+// Warning is a problem met while scraping a document that didn't fail the scrape, but
+// may have degraded its preview
+type Warning struct {
+	Kind WarningKind `json:"kind"`
+	// Url is the url of the document or of the resource the warning is about
+	Url     string `json:"url"`
+	Message string `json:"message"`
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s: %s", w.Kind, w.Url, w.Message)
+}
+
+func (doc *Document) warn(kind WarningKind, u string, format string, args ...interface{}) {
+	doc.Warnings = append(doc.Warnings, Warning{Kind: kind, Url: u, Message: fmt.Sprintf(format, args...)})
+}
@@ -0,0 +1,114 @@
+package goscraper
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// ZstdArchive writes the records to a writer as zstd compressed JSON lines, it's safe for
+// concurrent use. Close must be called to flush the end of the stream. ReplayArchive reads
+// the stream as is.
+type ZstdArchive struct {
+	mu  sync.Mutex
+	zw  *zstd.Encoder
+	enc *json.Encoder
+}
+
+func NewZstdArchive(w io.Writer) (*ZstdArchive, error) {
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return nil, err
+	}
+	return &ZstdArchive{zw: zw, enc: json.NewEncoder(zw)}, nil
+}
+
+func (a *ZstdArchive) Store(record *ArchiveRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.enc.Encode(record)
+}
+
+// Close flushes the records stored, it doesn't close the writer
+func (a *ZstdArchive) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.zw.Close()
+}
+
+// encoders and decoders are shared, they compress and decompress whole buffers concurrently
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// CompressRecord serializes a record to zstd compressed JSON, for the storage backends
+// keeping one record per key
+func CompressRecord(record *ArchiveRecord) ([]byte, error) {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	return zstdEncoder.EncodeAll(b, nil), nil
+}
+
+// DecompressRecord reads a record serialized by CompressRecord, or uncompressed JSON
+func DecompressRecord(b []byte) (*ArchiveRecord, error) {
+	if bytes.HasPrefix(b, zstdMagic) {
+		var err error
+		b, err = zstdDecoder.DecodeAll(b, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	record := &ArchiveRecord{}
+	if err := json.Unmarshal(b, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// CompressDocument serializes a document to zstd compressed JSON with MarshalJSON, its
+// BodyEncoding telling whether the body is stored
+func CompressDocument(doc *Document) ([]byte, error) {
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return zstdEncoder.EncodeAll(b, nil), nil
+}
+
+// DecompressDocument reads a document serialized by CompressDocument, or uncompressed JSON
+func DecompressDocument(b []byte) (*Document, error) {
+	if bytes.HasPrefix(b, zstdMagic) {
+		var err error
+		b, err = zstdDecoder.DecodeAll(b, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	doc := &Document{}
+	if err := json.Unmarshal(b, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+func decompressArchive(r io.Reader) (io.Reader, func(), error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(len(zstdMagic))
+	if !bytes.Equal(magic, zstdMagic) {
+		return br, func() {}, nil
+	}
+	zr, err := zstd.NewReader(br)
+	if err != nil {
+		return nil, nil, err
+	}
+	return zr, zr.Close, nil
+}